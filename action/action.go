@@ -2,6 +2,7 @@ package action
 
 import (
 	"context"
+	"net/http"
 	"strings"
 
 	"github.com/glennliao/apijson-go/config"
@@ -40,12 +41,19 @@ type Action struct {
 
 	ActionConfig *config.ActionConfig
 
+	// 执行器、hook 等的注册表, 为空时使用全局注册的
+	Registry *Registry
+
 	NewQuery  func(ctx context.Context, req model.Map) *query.Query
 	NewAction func(ctx context.Context, method string, req model.Map) *Action
 }
 
 func New(ctx context.Context, actionConfig *config.ActionConfig, method string, req model.Map) *Action {
 
+	if actionConfig == nil {
+		return &Action{ctx: ctx, method: method, req: req, err: consts.ErrNotLoaded}
+	}
+
 	request, err := checkTag(req, method, actionConfig)
 	if err != nil {
 		panic(err)
@@ -66,6 +74,13 @@ func New(ctx context.Context, actionConfig *config.ActionConfig, method string,
 	return a
 }
 
+func (a *Action) registry() *Registry {
+	if a.Registry == nil {
+		return defaultRegistry
+	}
+	return a.Registry
+}
+
 func (a *Action) parse() error {
 
 	structures := a.tagRequest.Structure
@@ -112,6 +127,10 @@ func (a *Action) parse() error {
 
 func (a *Action) Result() (model.Map, error) {
 
+	if a.err != nil {
+		return nil, a.err
+	}
+
 	err := a.parse()
 	if err != nil {
 		return nil, err
@@ -138,10 +157,9 @@ func (a *Action) Result() (model.Map, error) {
 
 	transactionHandler := noTransactionHandler
 
-	if *a.tagRequest.Transaction == true {
-		transactionResolver = GetTransactionHandler
-		h := transactionResolver(a.ctx, a)
-		if h != nil {
+	if a.needTransaction() {
+		h := GetTransactionHandler(a.ctx, a)
+		if h == nil {
 			err = consts.NewSysErr("transaction handler is nil")
 			return nil, err
 		}
@@ -176,6 +194,25 @@ func (a *Action) Result() (model.Map, error) {
 	return ret, err
 }
 
+// needTransaction 开启事务, 或设置了 AFFECTED、put 乐观锁需要在不满足时回滚, 审计需要修改前的行、修改与审计记录在同一事务中
+func (a *Action) needTransaction() bool {
+	if a.tagRequest.Transaction != nil && *a.tagRequest.Transaction {
+		return true
+	}
+
+	for _, k := range a.tagRequest.ExecQueue {
+		node, exists := a.children[k]
+		if !exists {
+			continue
+		}
+		if node.structure.Affected != "" || (a.method == http.MethodPut && node.versionColumn != "") || node.needAudit(a.method) {
+			return true
+		}
+	}
+
+	return false
+}
+
 func checkTag(req model.Map, method string, requestCfg *config.ActionConfig) (*config.RequestConfig, error) {
 	_tag, ok := req[consts.Tag]
 	if !ok {
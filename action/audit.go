@@ -0,0 +1,123 @@
+package action
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/glennliao/apijson-go/consts"
+	"github.com/glennliao/apijson-go/model"
+	"github.com/gogf/gf/v2/util/gconv"
+)
+
+// AuditRecord 审计记录, 每一行修改一条
+type AuditRecord struct {
+	Table     string
+	RowKey    any
+	Role      string
+	Method    string
+	Before    model.Map
+	After     model.Map // delete 时为空 (软删除时为删除后的行)
+	Tag       string
+	Version   string
+	CreatedAt time.Time
+}
+
+// AuditSink 审计记录的写入, 在写入数据的事务内调用
+type AuditSink interface {
+	Write(ctx context.Context, records []AuditRecord) error
+}
+
+// RowsFetcher 执行器可选实现, 用于审计时获取修改前后的行
+type RowsFetcher interface {
+	FetchRows(ctx context.Context, table string, where model.Map) ([]model.Map, error)
+}
+
+// RegAuditSink 全局注册审计, 作为各实例的默认值
+func RegAuditSink(s AuditSink) {
+	defaultRegistry.RegAuditSink(s)
+}
+
+// needAudit put/delete 时开启审计且注册了 AuditSink
+func (n *Node) needAudit(method string) bool {
+	if !n.auditEnabled || n.Action.registry().getAuditSink() == nil {
+		return false
+	}
+	return method == http.MethodPut || method == http.MethodDelete
+}
+
+// auditBefore 获取修改前的行 (事务内)
+func (n *Node) auditBefore(ctx context.Context, executor ActionExecutor) ([]model.Map, error) {
+	fetcher, ok := executor.(RowsFetcher)
+	if !ok {
+		return nil, consts.NewSysErr("action executor not support audit: " + n.executor)
+	}
+
+	var before []model.Map
+	for _, where := range n.Where {
+		rows, err := fetcher.FetchRows(ctx, n.tableName, where)
+		if err != nil {
+			return nil, err
+		}
+		before = append(before, rows...)
+	}
+
+	return before, nil
+}
+
+// audit 获取修改后的行, 并写入审计记录 (事务内)
+func (n *Node) audit(ctx context.Context, executor ActionExecutor, method string, before []model.Map) error {
+	if len(before) == 0 {
+		return nil
+	}
+
+	afterMap := map[string]model.Map{}
+
+	if (method == http.MethodPut || n.isSoftDelete(method)) && len(n.rowKeys) > 0 {
+		var rowKeys []any
+		for _, row := range before {
+			if len(n.rowKeys) == 1 {
+				rowKeys = append(rowKeys, row[n.rowKeys[0]])
+				continue
+			}
+			var tuple []any
+			for _, key := range n.rowKeys {
+				tuple = append(tuple, row[key])
+			}
+			rowKeys = append(rowKeys, tuple)
+		}
+
+		where := model.Map{strings.Join(n.rowKeys, ",") + consts.OpIn: rowKeys}
+		rows, err := executor.(RowsFetcher).FetchRows(ctx, n.tableName, where)
+		if err != nil {
+			return err
+		}
+		for _, row := range rows {
+			afterMap[gconv.String(n.rowKeyValue(row))] = row
+		}
+	}
+
+	now := time.Now()
+	records := make([]AuditRecord, 0, len(before))
+	for _, row := range before {
+		var rowKey any
+		if len(n.rowKeys) > 0 {
+			rowKey = n.rowKeyValue(row)
+		}
+
+		records = append(records, AuditRecord{
+			Table:     n.tableName,
+			RowKey:    rowKey,
+			Role:      n.Role,
+			Method:    method,
+			Before:    row,
+			After:     afterMap[gconv.String(rowKey)],
+			Tag:       n.Action.tagRequest.Tag,
+			Version:   n.Action.tagRequest.Version,
+			CreatedAt: now,
+		})
+	}
+
+	return n.Action.registry().getAuditSink().Write(ctx, records)
+}
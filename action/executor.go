@@ -4,10 +4,8 @@ import (
 	"context"
 
 	"github.com/glennliao/apijson-go/config"
-	"github.com/glennliao/apijson-go/consts"
 	"github.com/glennliao/apijson-go/model"
 	"github.com/glennliao/apijson-go/query"
-	"github.com/samber/lo"
 )
 
 type ActionExecutorReq struct {
@@ -18,26 +16,24 @@ type ActionExecutorReq struct {
 	Access   *config.AccessConfig
 	Config   *config.ActionConfig
 	NewQuery func(ctx context.Context, req model.Map) *query.Query
-}
 
-var actionExecutorMap = map[string]ActionExecutor{}
+	// upsert
+	Conflict        []string  // 冲突判断字段(唯一键)
+	ConflictUpdate  []string  // 冲突时使用新值更新的字段
+	ConflictReplace model.Map // 冲突时替换的值
+}
 
+// RegExecutor 全局注册执行器, 作为各实例的默认值
 func RegExecutor(name string, e ActionExecutor) {
-	actionExecutorMap[name] = e
+	defaultRegistry.RegExecutor(name, e)
 }
 
 func GetActionExecutor(name string) (ActionExecutor, error) {
-	if name == "" {
-		name = "default"
-	}
-	if v, exists := actionExecutorMap[name]; exists {
-		return v, nil
-	}
-	return nil, consts.NewSysErr("action executor not found: " + name)
+	return defaultRegistry.GetExecutor(name)
 }
 
 func ActionExecutorList() []string {
-	return lo.Keys(actionExecutorMap)
+	return defaultRegistry.ExecutorList()
 }
 
 type ActionExecutor interface {
@@ -54,12 +50,11 @@ var noTransactionHandler = func(ctx context.Context, action func(ctx context.Con
 	return action(ctx)
 }
 
-var transactionResolver TransactionResolver
-
+// RegTransactionResolver 全局注册事务, 作为各实例的默认值
 func RegTransactionResolver(r TransactionResolver) {
-	transactionResolver = r
+	defaultRegistry.RegTransactionResolver(r)
 }
 
 func GetTransactionHandler(ctx context.Context, req *Action) TransactionHandler {
-	return transactionResolver(ctx, req)
+	return req.registry().GetTransactionHandler(ctx, req)
 }
@@ -20,17 +20,14 @@ type Hook struct {
 	AfterExecutorDo  func(ctx context.Context, n *Node, method string) error
 }
 
-var hooksMap = map[string][]Hook{}
-
+// RegHook 全局注册 hook, 对所有实例生效
 func RegHook(h Hook) {
-	for _, item := range h.For {
-		hooksMap[item] = append(hooksMap[item], h)
-	}
+	defaultRegistry.RegHook(h)
 }
 
 func EmitHook(ctx context.Context, hookAt int, node *Node, method string) error {
 
-	hooks := append(hooksMap["*"], hooksMap[node.Key]...)
+	hooks := node.Action.registry().hooks(node.Key)
 	for _, hook := range hooks {
 
 		var handler func(ctx context.Context, n *Node, method string) error
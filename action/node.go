@@ -9,6 +9,8 @@ import (
 	"github.com/glennliao/apijson-go/consts"
 	"github.com/glennliao/apijson-go/model"
 	"github.com/glennliao/apijson-go/util"
+	"github.com/gogf/gf/v2/os/gtime"
+	"github.com/gogf/gf/v2/util/gconv"
 	"github.com/samber/lo"
 )
 
@@ -26,9 +28,28 @@ type Node struct {
 	Ret    model.Map   // 节点返回值
 	RowKey string      // 主键
 
+	rowKeys []string // 主键字段, 联合主键时有多个
+
+	Inserted []bool // upsert 时每行是否为新增, 否则为修改
+
 	structure *config.Structure
 	executor  string
 
+	returnColumns string // @return 写入后回查的字段
+
+	hasCondition bool // 是否有 access condition 限制的条件
+
+	versionColumn string // 乐观锁版本字段
+
+	softDeleteColumn string // 软删除字段
+	withDeleted      bool   // 包含已删除的行
+
+	tenantColumn string // 租户字段
+
+	auditEnabled bool // access 中开启了审计
+
+	autoFill map[string]*config.AutoFillValue // 自动填充的字段
+
 	keyNode map[string]*Node
 
 	// access *config.Access
@@ -65,20 +86,32 @@ func (n *Node) parseReq(method string) {
 		for key, val := range item {
 
 			if key == consts.Role {
-				n.Role = util.String(val)
 				continue
 			}
 
-			key = n.Action.DbFieldStyle(n.ctx, n.tableName, key)
+			if key == consts.Deleted {
+				n.withDeleted = gconv.Bool(val)
+				continue
+			}
+
+			if key == consts.Return {
+				n.returnColumns = util.String(val)
+				continue
+			}
+
+			key = n.dbKey(key)
 
 			switch method {
-			case http.MethodPost:
+			case http.MethodPost, consts.MethodUpsert:
 				n.Data[i][key] = val
 			case http.MethodDelete:
 				n.Where[i][key] = val
 			case http.MethodPut:
-				if key == n.RowKey || key == n.RowKey+consts.OpIn {
+				if lo.Contains(n.rowKeys, key) || key == strings.Join(n.rowKeys, ",")+consts.OpIn {
+					n.Where[i][key] = val
+				} else if n.versionColumn != "" && key == n.versionColumn {
 					n.Where[i][key] = val
+					n.Data[i][key+consts.OpPLus] = 1
 				} else {
 					n.Data[i][key] = val
 				}
@@ -103,9 +136,21 @@ func (n *Node) parse(ctx context.Context, method string) error {
 
 	n.tableName = access.Name
 	n.RowKey = access.RowKey
+	n.rowKeys = access.RowKeys()
+	n.versionColumn = access.VersionColumn
+	n.softDeleteColumn = access.SoftDeleteColumn
+	n.tenantColumn = access.TenantColumn
+	n.auditEnabled = access.Audit
+	n.autoFill = access.AutoFill
 
 	// 0. 角色替换
 
+	for _, item := range n.req {
+		if val, exists := item[consts.Role]; exists {
+			n.Role = util.String(val)
+		}
+	}
+
 	err = n.roleUpdate()
 	if err != nil {
 		return err
@@ -114,16 +159,24 @@ func (n *Node) parse(ctx context.Context, method string) error {
 	if n.Action.NoAccessVerify == false {
 		// 1. 检查权限, 无权限就不用做参数检查了
 
+		var requireRoles [][]string
 		switch method {
 		case http.MethodPost:
 			accessRoles = access.Post
+			requireRoles = [][]string{access.Post}
 		case http.MethodPut:
 			accessRoles = access.Put
+			requireRoles = [][]string{access.Put}
 		case http.MethodDelete:
 			accessRoles = access.Delete
+			requireRoles = [][]string{access.Delete}
+		case consts.MethodUpsert:
+			// 需同时有 post 与 put 的权限, 分别按继承的角色检查
+			accessRoles = lo.Intersect(access.Post, access.Put)
+			requireRoles = [][]string{access.Post, access.Put}
 		}
 
-		err = n.checkAccess(ctx, method, accessRoles)
+		err = n.checkAccess(ctx, method, requireRoles...)
 		if err != nil {
 			return err
 		}
@@ -136,11 +189,121 @@ func (n *Node) parse(ctx context.Context, method string) error {
 	}
 
 	// 3. get where by accessCondition
-	err = n.whereUpdate(ctx, method, accessRoles)
+	err = n.whereUpdate(ctx, method, accessRoles, access)
+	if err != nil {
+		return err
+	}
+
+	err = n.coerceReq(access)
+	if err != nil {
+		return err
+	}
 
 	n.parseReq(method)
 
-	return err
+	err = n.checkAutoFill(method)
+	if err != nil {
+		return err
+	}
+
+	err = n.checkRowKeys(ctx, method)
+	if err != nil {
+		return err
+	}
+
+	if n.Action.NoAccessVerify == false {
+		err = n.checkFieldsWrite(ctx, method, access)
+		if err != nil {
+			return err
+		}
+
+		if method == http.MethodPost {
+			for i := range n.Data {
+				err = access.RowPolicyData(ctx, n.Role, n.Data[i])
+				if err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	if n.softDeleteColumn != "" {
+		err = n.softDeleteUpdate(method)
+		if err != nil {
+			return err
+		}
+	}
+
+	if n.tenantColumn != "" {
+		err = n.tenantUpdate(ctx, method, access)
+		if err != nil {
+			return err
+		}
+	}
+
+	if method == http.MethodPut && n.versionColumn != "" {
+		for _, where := range n.Where {
+			if _, exists := where[n.versionColumn]; !exists {
+				return consts.NewStructureKeyNoFoundErr(n.Key + "." + n.Action.JsonFieldStyle(ctx, n.tableName, n.versionColumn))
+			}
+		}
+	}
+
+	if n.returnColumns != "" && method != http.MethodPost {
+		return consts.NewValidReqErr(consts.Return + " only support POST: " + n.Key)
+	}
+
+	return nil
+}
+
+// coerceReq 按表结构检查请求中的字段是否存在, 并转换值的类型; 表不在 DbMeta 中时不检查
+func (n *Node) coerceReq(access *config.AccessConfig) error {
+	table := n.Action.ActionConfig.DbMeta().GetTable(access.Name)
+	if table == nil {
+		return nil
+	}
+
+	for _, item := range n.req {
+		for key, val := range item {
+			if strings.HasPrefix(key, consts.CtrlKeyPrefix) {
+				continue
+			}
+			ret, err := table.CoerceReq(n.dbKey(key), val)
+			if err != nil {
+				return consts.NewValidReqErr(n.Key + ": " + err.Error())
+			}
+			item[key] = ret
+		}
+	}
+	return nil
+}
+
+// checkFieldsWrite 检查写入的字段是否在角色可写入的字段中
+func (n *Node) checkFieldsWrite(ctx context.Context, method string, access *config.AccessConfig) error {
+	fields, limited := access.GetFieldsWriteByRole(method, n.Role)
+	if !limited {
+		return nil
+	}
+
+	// 只检查前端传入的字段, 不包括 access condition 等加入的值; put 时的主键为条件
+	tupleKey := strings.Join(n.rowKeys, ",") + consts.OpIn
+	for _, item := range n.req {
+		for key := range item {
+			if strings.HasPrefix(key, "@") {
+				continue
+			}
+			field := n.dbKey(strings.TrimSuffix(strings.TrimSuffix(key, consts.OpPLus), consts.OpSub))
+			if method == http.MethodPut && (lo.Contains(n.rowKeys, field) || field == tupleKey) {
+				continue
+			}
+			if field == n.versionColumn || lo.Contains(fields, field) {
+				continue
+			}
+			return consts.NewNoAccessErr(n.Key+"."+n.Action.JsonFieldStyle(ctx, n.tableName, field), n.Role)
+		}
+	}
+
+	return nil
 }
 
 // update node role
@@ -159,7 +322,8 @@ func (n *Node) roleUpdate() error {
 	return nil
 }
 
-func (n *Node) checkAccess(ctx context.Context, method string, accessRoles []string) error {
+// checkAccess 确定节点的角色, 角色或其继承的角色需在 accessRoles 的每一组中
+func (n *Node) checkAccess(ctx context.Context, method string, accessRoles ...[]string) error {
 
 	role, err := n.Action.ActionConfig.DefaultRoleFunc()(ctx, config.RoleReq{
 		AccessName: n.tableName,
@@ -177,14 +341,19 @@ func (n *Node) checkAccess(ctx context.Context, method string, accessRoles []str
 
 	n.Role = role
 
-	if !lo.Contains(accessRoles, role) {
+	if len(accessRoles) == 0 {
 		return consts.NewNoAccessErr(n.Key, n.Role)
 	}
+	for _, roles := range accessRoles {
+		if !n.Action.ActionConfig.HasRole(roles, role) {
+			return consts.NewNoAccessErr(n.Key, n.Role)
+		}
+	}
 
 	return nil
 }
 
-func (n *Node) whereUpdate(ctx context.Context, method string, accessRoles []string) error {
+func (n *Node) whereUpdate(ctx context.Context, method string, accessRoles []string, access *config.AccessConfig) error {
 
 	for i, item := range n.req {
 
@@ -204,8 +373,35 @@ func (n *Node) whereUpdate(ctx context.Context, method string, accessRoles []str
 			return err
 		}
 
+		if method != http.MethodPost && !n.Action.NoAccessVerify {
+			err = access.RowPolicyWhere(ctx, method, n.Role, condition)
+			if err != nil {
+				return err
+			}
+		}
+
+		if !n.Action.NoAccessVerify {
+			err = access.EvalRules(ctx, method, n.Role, item, condition)
+			if err != nil {
+				return err
+			}
+		}
+
+		if len(condition.Where()) > 0 {
+			n.hasCondition = true
+
+			if method == consts.MethodUpsert {
+				// upsert 冲突时会修改已存在的行, 无法按 condition 限制
+				return consts.NewNoAccessErr(n.Key+"."+consts.MethodUpsert, n.Role)
+			}
+		}
+
 		if method == http.MethodPost {
 			for k, v := range condition.Where() {
+				if k == consts.Raw || k == consts.RawAnd {
+					// 原始条件用于筛选已有的行, 不是新增的值
+					continue
+				}
 				n.Data[i][k] = v
 			}
 		} else {
@@ -299,8 +495,84 @@ func (n *Node) reqUpdate() error {
 			}
 		}
 
+		err := n.autoFillUpdate(i)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// checkAutoFill 请求中不能包含自动填充的字段; 只检查前端传入的, 不包括 access condition 等加入的值
+func (n *Node) checkAutoFill(method string) error {
+	if method == http.MethodDelete || len(n.autoFill) == 0 {
+		return nil
 	}
 
+	for _, item := range n.req {
+		for key := range item {
+			if strings.HasPrefix(key, "@") {
+				continue
+			}
+			field := strings.TrimSuffix(strings.TrimSuffix(key, consts.OpPLus), consts.OpSub)
+			if _, exists := n.autoFill[n.dbKey(field)]; exists {
+				return consts.NewValidStructureErr("不能包含:" + n.Key + "." + key)
+			}
+		}
+	}
+	return nil
+}
+
+// checkRowKeys 联合主键时 put/delete 需传入全部主键字段, 或 userId,roleId{} 形式的主键列表
+func (n *Node) checkRowKeys(ctx context.Context, method string) error {
+	if len(n.rowKeys) < 2 || (method != http.MethodPut && method != http.MethodDelete) {
+		return nil
+	}
+
+	tupleKey := strings.Join(n.rowKeys, ",") + consts.OpIn
+	for _, item := range n.req {
+		keys := map[string]bool{}
+		for key := range item {
+			keys[n.dbKey(key)] = true
+		}
+		if keys[tupleKey] {
+			continue
+		}
+		for _, rowKey := range n.rowKeys {
+			if !keys[rowKey] {
+				return consts.NewStructureKeyNoFoundErr(n.Key + "." + n.Action.JsonFieldStyle(ctx, n.tableName, rowKey))
+			}
+		}
+	}
+	return nil
+}
+
+// autoFillUpdate 填充 access 中配置的自动填充字段, 覆盖 structure 中的值
+func (n *Node) autoFillUpdate(i int) error {
+	for column, fill := range n.autoFill {
+		if fill == nil {
+			continue
+		}
+
+		var val any
+		switch n.Action.method {
+		case http.MethodPost, consts.MethodUpsert:
+			val = fill.Post
+		case http.MethodPut:
+			val = fill.Put
+		}
+
+		if val == nil {
+			continue
+		}
+
+		val, err := n.Action.ActionConfig.ResolveValue(n.ctx, val)
+		if err != nil {
+			return err
+		}
+		n.Data[i][column] = val
+	}
 	return nil
 }
 
@@ -341,11 +613,15 @@ func (n *Node) do(ctx context.Context, method string) (ret model.Map, err error)
 	}
 
 	switch method {
-	case http.MethodPost:
+	case http.MethodPost, consts.MethodUpsert:
 
 		if access.RowKeyGen != "" {
 			for i, _ := range n.Data {
 
+				if method == consts.MethodUpsert && n.hasRowKey(n.Data[i]) {
+					continue
+				}
+
 				rowKeyVal, err = n.Action.ActionConfig.RowKeyGen(ctx, access.RowKeyGen, n.Key, n.Data[i])
 				if err != nil {
 					return nil, err
@@ -353,6 +629,9 @@ func (n *Node) do(ctx context.Context, method string) (ret model.Map, err error)
 
 				for k, v := range rowKeyVal {
 					if k == consts.RowKey {
+						if len(n.rowKeys) > 1 {
+							return nil, consts.NewSysErr("composite rowKey need RowKeys: " + n.Key)
+						}
 						n.Data[i][access.RowKey] = v
 					} else {
 						n.Data[i][k] = v
@@ -371,12 +650,12 @@ func (n *Node) do(ctx context.Context, method string) (ret model.Map, err error)
 		return nil, consts.NewMethodNotSupportErr(method)
 	}
 
-	executor, err := GetActionExecutor(n.executor)
+	executor, err := n.Action.registry().GetExecutor(n.executor)
 	if err != nil {
 		return nil, err
 	}
 
-	ret, err = executor.Do(ctx, ActionExecutorReq{
+	executorReq := ActionExecutorReq{
 		Method:   method,
 		Table:    n.tableName,
 		Data:     n.Data,
@@ -384,15 +663,82 @@ func (n *Node) do(ctx context.Context, method string) (ret model.Map, err error)
 		Access:   access,
 		Config:   n.Action.ActionConfig,
 		NewQuery: n.Action.NewQuery,
-	})
+	}
+
+	if method == consts.MethodUpsert {
+		n.conflict(&executorReq)
+
+		// 冲突字段不含租户字段时, 可能修改到其他租户的行
+		if n.tenantColumn != "" && !lo.Contains(executorReq.Conflict, n.tenantColumn) {
+			return nil, consts.NewNoAccessErr(n.Key+"."+consts.MethodUpsert, n.Role)
+		}
+	}
+
+	if n.isSoftDelete(method) {
+		executorReq.Method = http.MethodPut
+		now := gtime.Now()
+		for i := range n.Data {
+			n.Data[i][n.softDeleteColumn] = now
+		}
+	}
+
+	var before []model.Map
+	if n.needAudit(method) {
+		before, err = n.auditBefore(ctx, executor)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	ret, err = executor.Do(ctx, executorReq)
 
 	if err != nil {
 		return nil, err
 	}
 
+	if method == http.MethodPut && n.versionColumn != "" {
+		err = n.checkVersion(ctx, executor, ret)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = n.checkAffected(ret)
+	if err != nil {
+		return nil, err
+	}
+
+	if before != nil {
+		err = n.audit(ctx, executor, method, before)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if v, exists := ret[consts.InsertedList]; exists {
+		n.Inserted = nil
+		for _, item := range gconv.Interfaces(v) {
+			n.Inserted = append(n.Inserted, gconv.Bool(item))
+		}
+	}
+
+	jsonStyle := n.Action.JsonFieldStyle
+
+	if len(n.rowKeys) > 1 {
+		if v, exists := ret[consts.Id]; exists {
+			ret[consts.Id] = n.jsonRowKey(v)
+		}
+		if v, exists := ret[consts.IdList]; exists {
+			ids := gconv.Interfaces(v)
+			for i := range ids {
+				ids[i] = n.jsonRowKey(ids[i])
+			}
+			ret[consts.IdList] = ids
+		}
+	}
+
 	if len(n.Data) == 1 {
 
-		jsonStyle := n.Action.JsonFieldStyle
 		if rowKeyVal != nil {
 			for k, v := range rowKeyVal {
 				if k == consts.RowKey {
@@ -404,6 +750,13 @@ func (n *Node) do(ctx context.Context, method string) (ret model.Map, err error)
 		}
 	}
 
+	if n.returnColumns != "" {
+		ret[consts.Return], err = n.returning(ctx, ret)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	n.Ret = ret
 
 	err = EmitHook(ctx, AfterExecutorDo, n, method)
@@ -414,6 +767,329 @@ func (n *Node) do(ctx context.Context, method string) (ret model.Map, err error)
 	return
 }
 
+// affectedCounts 每一项的影响行数; 单项时可使用 count, 多项时执行器需返回与请求项数相同的 count[]
+func (n *Node) affectedCounts(ret model.Map) ([]int64, error) {
+	v, exists := ret[consts.CountList]
+	if !exists {
+		if len(n.req) == 1 {
+			return []int64{gconv.Int64(ret["count"])}, nil
+		}
+		return nil, consts.NewSysErr("executor not return " + consts.CountList + ": " + n.Key)
+	}
+
+	var counts []int64
+	for _, item := range gconv.Interfaces(v) {
+		counts = append(counts, gconv.Int64(item))
+	}
+	if len(counts) != len(n.req) {
+		return nil, consts.NewSysErr("executor " + consts.CountList + " not match the request: " + n.Key)
+	}
+	return counts, nil
+}
+
+// checkAffected 检查每一项的影响行数是否符合 structure 的 AFFECTED
+// 不符合时, 若有 access condition 限制则视为无权限, 否则为数据不存在
+func (n *Node) checkAffected(ret model.Map) error {
+	if n.structure.Affected == "" {
+		return nil
+	}
+
+	counts, err := n.affectedCounts(ret)
+	if err != nil {
+		return err
+	}
+
+	for _, count := range counts {
+		ok, err := n.structure.MatchAffected(count)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			if n.hasCondition {
+				return consts.NewNoAccessErr(n.Key, n.Role)
+			}
+			return consts.NewRowNoFoundErr(n.Key)
+		}
+	}
+
+	return nil
+}
+
+// checkVersion 乐观锁, 任一项未修改到数据时: 行存在为版本冲突, 否则同 checkAffected; 单项时返回新的版本号
+func (n *Node) checkVersion(ctx context.Context, executor ActionExecutor, ret model.Map) error {
+	counts, err := n.affectedCounts(ret)
+	if err != nil {
+		return err
+	}
+
+	for i, count := range counts {
+		if count == 0 {
+			return n.versionErr(ctx, executor, i)
+		}
+	}
+
+	if len(n.Where) == 1 {
+		ret[n.Action.JsonFieldStyle(ctx, n.tableName, n.versionColumn)] = gconv.Int64(n.Where[0][n.versionColumn]) + 1
+	}
+
+	return nil
+}
+
+// versionErr 未修改到数据时, 去掉版本条件查询第 i 项的行是否存在 (事务内); 执行器不支持查询时, 有 condition 限制视为无权限
+func (n *Node) versionErr(ctx context.Context, executor ActionExecutor, i int) error {
+	exists := !n.hasCondition
+	if fetcher, ok := executor.(RowsFetcher); ok {
+		where := model.Map{}
+		for k, v := range n.Where[i] {
+			if k != n.versionColumn {
+				where[k] = v
+			}
+		}
+		rows, err := fetcher.FetchRows(ctx, n.tableName, where)
+		if err != nil {
+			return err
+		}
+		exists = len(rows) > 0
+	}
+
+	switch {
+	case exists:
+		return consts.NewVersionConflictErr(n.Key)
+	case n.hasCondition:
+		return consts.NewNoAccessErr(n.Key, n.Role)
+	default:
+		return consts.NewRowNoFoundErr(n.Key)
+	}
+}
+
+// softDeleteUpdate 软删除, put/delete 排除已删除的行; @deleted 仅ADMIN可用 (NoAccessVerify 时不限制)
+func (n *Node) softDeleteUpdate(method string) error {
+	if n.withDeleted {
+		if !n.Action.NoAccessVerify && !n.Action.ActionConfig.IsRole(n.Role, consts.ADMIN) {
+			return consts.NewNoAccessErr(n.Key+"."+consts.Deleted, n.Role)
+		}
+		return nil
+	}
+
+	if method != http.MethodPut && method != http.MethodDelete {
+		return nil
+	}
+
+	for i := range n.Where {
+		addRawWhere(n.Where[i], n.softDeleteColumn, nil)
+	}
+
+	return nil
+}
+
+// tenantUpdate 多租户, post/upsert 写入当前租户id, put/delete 只能修改当前租户的行; 请求中不能包含租户字段
+func (n *Node) tenantUpdate(ctx context.Context, method string, access *config.AccessConfig) error {
+	for _, item := range n.req {
+		err := access.CheckTenantKeys(ctx, item, n.Action.DbFieldStyle)
+		if err != nil {
+			return err
+		}
+	}
+
+	tenantId, err := access.TenantId(ctx)
+	if err != nil {
+		return err
+	}
+
+	switch method {
+	case http.MethodPost, consts.MethodUpsert:
+		for i := range n.Data {
+			n.Data[i][n.tenantColumn] = tenantId
+		}
+	case http.MethodPut, http.MethodDelete:
+		for i := range n.Where {
+			addRawWhere(n.Where[i], n.tenantColumn, tenantId)
+		}
+	}
+
+	return nil
+}
+
+// addRawWhere 在 where 中添加原始条件, 同 ConditionRet.AddRaw
+func addRawWhere(where model.Map, k string, v any) {
+	raw, _ := where[consts.Raw].(map[string]any)
+	if _, exists := raw[k]; exists {
+		rawAnd, _ := where[consts.RawAnd].([]map[string]any)
+		where[consts.RawAnd] = append(rawAnd, map[string]any{k: v})
+		return
+	}
+
+	copied := map[string]any{k: v}
+	for key, val := range raw {
+		copied[key] = val
+	}
+	where[consts.Raw] = copied
+}
+
+// isSoftDelete delete 时设置软删除字段, ADMIN 使用 @deleted 时为物理删除
+func (n *Node) isSoftDelete(method string) bool {
+	return method == http.MethodDelete && n.softDeleteColumn != "" && !n.withDeleted
+}
+
+// conflict 设置 upsert 的冲突字段与冲突时的修改内容
+// 冲突字段为 UNIQUE, 未设置则使用 rowKey; INSERT 中的字段只在新增时写入, REPLACE 为冲突时替换的值
+func (n *Node) conflict(req *ActionExecutorReq) {
+	dbStyle := n.Action.DbFieldStyle
+
+	if len(n.structure.Unique) > 0 {
+		for _, key := range n.structure.Unique {
+			req.Conflict = append(req.Conflict, dbStyle(n.ctx, n.tableName, key))
+		}
+	} else if len(n.rowKeys) > 0 {
+		req.Conflict = n.rowKeys
+	}
+
+	var insertOnly []string
+	for key := range n.structure.Insert {
+		insertOnly = append(insertOnly, dbStyle(n.ctx, n.tableName, key))
+	}
+	for column, fill := range n.autoFill {
+		if fill != nil && fill.Post != nil && fill.Put == nil {
+			insertOnly = append(insertOnly, column)
+		}
+	}
+
+	if len(n.Data) > 0 {
+		for key := range n.Data[0] {
+			if lo.Contains(n.rowKeys, key) || lo.Contains(req.Conflict, key) || lo.Contains(insertOnly, key) || key == n.tenantColumn {
+				continue
+			}
+			req.ConflictUpdate = append(req.ConflictUpdate, key)
+		}
+	}
+
+	if len(n.structure.Replace) > 0 {
+		req.ConflictReplace = model.Map{}
+		for key, val := range n.structure.Replace {
+			req.ConflictReplace[dbStyle(n.ctx, n.tableName, key)] = val
+		}
+	}
+}
+
+// returning 按 @return 的字段, 使用get的权限规则回查刚写入的行 (事务内)
+func (n *Node) returning(ctx context.Context, ret model.Map) (any, error) {
+	if len(n.rowKeys) == 0 {
+		return nil, consts.NewValidReqErr(consts.Return + " need rowKey: " + n.Key)
+	}
+
+	var ids []any
+	if v, exists := ret[consts.IdList]; exists {
+		ids = gconv.Interfaces(v)
+	} else if v, exists := ret[consts.Id]; exists {
+		ids = []any{v}
+	}
+
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	var list []model.Map
+
+	if len(n.rowKeys) > 1 {
+		// 联合主键无法使用 {} 查询, 逐行回查
+		for _, id := range ids {
+			req := model.Map{
+				consts.Column: n.returnColumns,
+				consts.Role:   n.Role,
+			}
+			for k, v := range gconv.Map(id) {
+				req[k] = v
+			}
+
+			result, err := n.Action.NewQuery(ctx, model.Map{n.Key: req}).Result()
+			if err != nil {
+				return nil, err
+			}
+			if row, ok := result[n.Key].(model.Map); ok && row != nil {
+				list = append(list, row)
+			}
+		}
+	} else {
+		listKey := n.Key + consts.ListKeySuffix
+		rowKey := n.Action.JsonFieldStyle(ctx, n.tableName, n.RowKey)
+
+		q := n.Action.NewQuery(ctx, model.Map{
+			listKey: model.Map{
+				rowKey + consts.OpIn: ids,
+				consts.Column:        n.returnColumns,
+				consts.Role:          n.Role,
+				consts.Count:         len(ids),
+			},
+		})
+
+		result, err := q.Result()
+		if err != nil {
+			return nil, err
+		}
+
+		list, _ = result[listKey].([]model.Map)
+	}
+
+	if n.IsList {
+		return list, nil
+	}
+
+	if len(list) == 0 {
+		return nil, nil
+	}
+	return list[0], nil
+}
+
+// dbKey 请求字段转为数据库字段, 联合主键的 {} 形式 userId,roleId{} 逐个转换
+func (n *Node) dbKey(key string) string {
+	if strings.HasSuffix(key, consts.OpIn) && strings.Contains(key, ",") {
+		fields := strings.Split(util.RemoveSuffix(key, consts.OpIn), ",")
+		for i, field := range fields {
+			fields[i] = n.Action.DbFieldStyle(n.ctx, n.tableName, strings.TrimSpace(field))
+		}
+		return strings.Join(fields, ",") + consts.OpIn
+	}
+	return n.Action.DbFieldStyle(n.ctx, n.tableName, key)
+}
+
+// hasRowKey 数据中是否已有完整的主键值
+func (n *Node) hasRowKey(data model.Map) bool {
+	if len(n.rowKeys) == 0 {
+		return false
+	}
+	for _, key := range n.rowKeys {
+		if data[key] == nil {
+			return false
+		}
+	}
+	return true
+}
+
+// rowKeyValue 行的主键值, 联合主键时为 字段->值
+func (n *Node) rowKeyValue(row model.Map) any {
+	if len(n.rowKeys) == 1 {
+		return row[n.rowKeys[0]]
+	}
+	val := model.Map{}
+	for _, key := range n.rowKeys {
+		val[key] = row[key]
+	}
+	return val
+}
+
+// jsonRowKey 联合主键值的字段转为json风格
+func (n *Node) jsonRowKey(v any) any {
+	m, ok := v.(model.Map)
+	if !ok {
+		return v
+	}
+	val := model.Map{}
+	for k, item := range m {
+		val[n.Action.JsonFieldStyle(n.ctx, n.tableName, k)] = item
+	}
+	return val
+}
+
 func (n *Node) execute(ctx context.Context, method string) (model.Map, error) {
 
 	err := n.reqUpdateBeforeDo()
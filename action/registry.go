@@ -0,0 +1,91 @@
+package action
+
+import (
+	"context"
+
+	"github.com/glennliao/apijson-go/consts"
+	"github.com/samber/lo"
+)
+
+// Registry 执行器、hook、事务、审计的注册表, 每个 ApiJson 实例持有一个
+// 实例中未注册的执行器、事务、审计使用全局注册的 (RegExecutor/RegTransactionResolver/RegAuditSink); hook 为全局注册的与实例注册的依次执行
+type Registry struct {
+	executors           map[string]ActionExecutor
+	hooksMap            map[string][]Hook
+	transactionResolver TransactionResolver
+	auditSink           AuditSink
+}
+
+func NewRegistry() *Registry {
+	return &Registry{
+		executors: map[string]ActionExecutor{},
+		hooksMap:  map[string][]Hook{},
+	}
+}
+
+// defaultRegistry 全局注册表, 作为各实例的默认值
+var defaultRegistry = NewRegistry()
+
+func (r *Registry) RegExecutor(name string, e ActionExecutor) {
+	r.executors[name] = e
+}
+
+func (r *Registry) RegHook(h Hook) {
+	for _, item := range h.For {
+		r.hooksMap[item] = append(r.hooksMap[item], h)
+	}
+}
+
+func (r *Registry) RegTransactionResolver(resolver TransactionResolver) {
+	r.transactionResolver = resolver
+}
+
+func (r *Registry) RegAuditSink(s AuditSink) {
+	r.auditSink = s
+}
+
+// GetExecutor 获取执行器, 实例中未注册时使用全局注册的
+func (r *Registry) GetExecutor(name string) (ActionExecutor, error) {
+	if name == "" {
+		name = "default"
+	}
+	if v, exists := r.executors[name]; exists {
+		return v, nil
+	}
+	if v, exists := defaultRegistry.executors[name]; exists {
+		return v, nil
+	}
+	return nil, consts.NewSysErr("action executor not found: " + name)
+}
+
+func (r *Registry) ExecutorList() []string {
+	return lo.Union(lo.Keys(defaultRegistry.executors), lo.Keys(r.executors))
+}
+
+// hooks 节点的 hook, 全局注册的在前
+func (r *Registry) hooks(key string) []Hook {
+	var hooks []Hook
+	for _, registry := range lo.Uniq([]*Registry{defaultRegistry, r}) {
+		hooks = append(hooks, registry.hooksMap["*"]...)
+		hooks = append(hooks, registry.hooksMap[key]...)
+	}
+	return hooks
+}
+
+func (r *Registry) GetTransactionHandler(ctx context.Context, req *Action) TransactionHandler {
+	resolver := r.transactionResolver
+	if resolver == nil {
+		resolver = defaultRegistry.transactionResolver
+	}
+	if resolver == nil {
+		return nil
+	}
+	return resolver(ctx, req)
+}
+
+func (r *Registry) getAuditSink() AuditSink {
+	if r.auditSink != nil {
+		return r.auditSink
+	}
+	return defaultRegistry.auditSink
+}
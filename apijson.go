@@ -9,14 +9,18 @@ import (
 	"github.com/glennliao/apijson-go/query"
 )
 
-type Plugin interface {
-	Install(ctx context.Context, a *ApiJson)
-}
-
 type ApiJson struct {
 	config *config.Config
 	Debug  bool // 是否开启debug模式, 显示每步骤
 	ctx    context.Context
+
+	// 实例的执行器、hook 等, 未注册的使用全局注册的
+	queryExecutors *query.Executors
+	actionRegistry *action.Registry
+
+	plugins   []Plugin // UsePlugin 注册的插件, 按注册顺序
+	installed []Plugin // 已安装的插件, 按依赖排序
+	started   []Plugin // 已启动的插件
 }
 
 var DefaultApiJson = New()
@@ -24,6 +28,8 @@ var DefaultApiJson = New()
 func New() *ApiJson {
 	a := &ApiJson{}
 	a.config = config.New()
+	a.queryExecutors = query.NewExecutors()
+	a.actionRegistry = action.NewRegistry()
 	a.ctx = context.Background()
 	return a
 }
@@ -47,34 +53,72 @@ func (a *ApiJson) Use(p ...func(ctx context.Context, a *ApiJson)) *ApiJson {
 }
 
 func (a *ApiJson) Load() {
+	a.installPlugins()
 	a.config.ReLoad()
 }
 
+// Reload 重新加载配置, 失败时保留原配置; 进行中的请求继续使用原配置
+func (a *ApiJson) Reload(ctx context.Context) error {
+	return a.config.Reload(ctx)
+}
+
 func (a *ApiJson) Config() *config.Config {
 	return a.config
 }
 
+// QueryExecutors 实例的 query 执行器注册表
+func (a *ApiJson) QueryExecutors() *query.Executors {
+	return a.queryExecutors
+}
+
+// ActionRegistry 实例的 action 执行器、hook、事务、审计注册表
+func (a *ApiJson) ActionRegistry() *action.Registry {
+	return a.actionRegistry
+}
+
 func (a *ApiJson) NewQuery(ctx context.Context, req model.Map) *query.Query {
-	q := query.New(ctx, a.Config().QueryConfig(), req)
+	return a.newQuery(ctx, a.config.Snapshot(), req)
+}
+
+// newQuery snapshot 为 nil (未 Load) 时, Result 返回错误
+func (a *ApiJson) newQuery(ctx context.Context, snapshot *config.Snapshot, req model.Map) *query.Query {
+	if snapshot == nil {
+		return query.New(ctx, nil, req)
+	}
 
-	q.DbMeta = a.config.DbMeta
+	q := query.New(ctx, snapshot.QueryConfig(), req)
+
+	q.DbMeta = snapshot.DbMeta()
 	q.DbFieldStyle = a.config.DbFieldStyle
 	q.JsonFieldStyle = a.config.JsonFieldStyle
 
+	// 函数与 NoVerify 使用当前的设置, 不随快照固定
 	q.NoAccessVerify = a.config.Access.NoVerify
 	q.AccessCondition = a.config.Access.ConditionFunc
 
+	q.Executors = a.queryExecutors
+
 	return q
 }
 
 func (a *ApiJson) NewAction(ctx context.Context, method string, req model.Map) *action.Action {
-	act := action.New(ctx, a.Config().ActionConfig(), method, req)
+	// action 中回查、审计等使用同一快照
+	snapshot := a.config.Snapshot()
+	if snapshot == nil {
+		return action.New(ctx, nil, method, req)
+	}
+
+	act := action.New(ctx, snapshot.ActionConfig(), method, req)
 
 	act.NoAccessVerify = a.config.Access.NoVerify
 	act.DbFieldStyle = a.config.DbFieldStyle
 	act.JsonFieldStyle = a.config.JsonFieldStyle
 
-	act.NewQuery = a.NewQuery
+	act.Registry = a.actionRegistry
+
+	act.NewQuery = func(ctx context.Context, req model.Map) *query.Query {
+		return a.newQuery(ctx, snapshot, req)
+	}
 
 	return act
 }
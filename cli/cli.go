@@ -0,0 +1,254 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/glennliao/apijson-go"
+	"github.com/glennliao/apijson-go/config"
+	"github.com/glennliao/apijson-go/openapi"
+	"github.com/samber/lo"
+)
+
+// 命令的输出
+var (
+	Stdout io.Writer = os.Stdout
+	Stderr io.Writer = os.Stderr
+)
+
+// Command 子命令, 返回进程的退出码
+type Command struct {
+	Usage string
+	Run   func(ctx context.Context, a *apijson.ApiJson, args []string) int
+}
+
+var commandMap = map[string]Command{}
+
+// RegCommand 注册子命令
+func RegCommand(name string, cmd Command) {
+	commandMap[name] = cmd
+}
+
+func init() {
+	RegCommand("lint", Command{Usage: "检查 _access 与 _request 配置", Run: lint})
+	RegCommand("schema", Command{Usage: "导出表结构, 用于 lint -schema", Run: schema})
+	RegCommand("openapi", Command{Usage: "导出 OpenAPI 文档或 _request 的 JSON Schema", Run: openApi})
+	RegCommand("typescript", Command{Usage: "生成前端使用的 TypeScript 类型与请求函数", Run: typeScript})
+}
+
+// Run 执行子命令, 在应用的 main 中使用, 以使用应用中注册的 provider、函数与执行器, 如
+//
+//	if len(os.Args) > 1 && os.Args[1] == "apijson" {
+//		os.Exit(cli.Run(ctx, a, os.Args[2:]))
+//	}
+func Run(ctx context.Context, a *apijson.ApiJson, args []string) int {
+	if len(args) == 0 {
+		usage()
+		return 2
+	}
+
+	cmd, exists := commandMap[args[0]]
+	if !exists {
+		fmt.Fprintf(Stderr, "unknown command %s\n", args[0])
+		usage()
+		return 2
+	}
+
+	return cmd.Run(ctx, a, args[1:])
+}
+
+func usage() {
+	names := lo.Keys(commandMap)
+	sort.Strings(names)
+	fmt.Fprintln(Stderr, "commands:")
+	for _, name := range names {
+		fmt.Fprintf(Stderr, "  %-10s %s\n", name, commandMap[name].Usage)
+	}
+}
+
+func newFlagSet(name string) *flag.FlagSet {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	flags.SetOutput(Stderr)
+	return flags
+}
+
+// lint 有问题时退出码为 1
+func lint(ctx context.Context, a *apijson.ApiJson, args []string) int {
+	flags := newFlagSet("lint")
+	schemaFile := flags.String("schema", "", "schema 命令导出的表结构文件, 为空时使用 DbMetaProvider")
+	format := flags.String("format", "text", "输出格式 text 或 json")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	var dbMeta *config.DBMeta
+	if *schemaFile != "" {
+		content, err := os.ReadFile(*schemaFile)
+		if err != nil {
+			fmt.Fprintln(Stderr, err)
+			return 2
+		}
+		var tables []config.Table
+		if err = json.Unmarshal(content, &tables); err != nil {
+			fmt.Fprintf(Stderr, "%s: %s\n", *schemaFile, err)
+			return 2
+		}
+		dbMeta = config.NewDbMeta(tables)
+	}
+
+	problems, err := a.Lint(ctx, dbMeta)
+	if err != nil {
+		fmt.Fprintln(Stderr, err)
+		return 2
+	}
+
+	if *format == "json" {
+		if problems == nil {
+			problems = []config.Problem{}
+		}
+		enc := json.NewEncoder(Stdout)
+		enc.SetIndent("", "  ")
+		_ = enc.Encode(problems)
+	} else {
+		for _, p := range problems {
+			fmt.Fprintln(Stdout, p)
+		}
+	}
+
+	if len(problems) > 0 {
+		return 1
+	}
+	return 0
+}
+
+func schema(ctx context.Context, a *apijson.ApiJson, args []string) int {
+	flags := newFlagSet("schema")
+	output := flags.String("o", "", "输出文件, 为空时输出到 stdout")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	dbMeta, err := a.LoadDbMeta(ctx)
+	if err != nil {
+		fmt.Fprintln(Stderr, err)
+		return 2
+	}
+
+	var tables []config.Table
+	if dbMeta != nil {
+		tables = dbMeta.Tables()
+	}
+
+	content, err := json.MarshalIndent(tables, "", "  ")
+	if err != nil {
+		fmt.Fprintln(Stderr, err)
+		return 2
+	}
+
+	if *output == "" {
+		fmt.Fprintln(Stdout, string(content))
+		return 0
+	}
+
+	if err = os.WriteFile(*output, append(content, '\n'), 0644); err != nil {
+		fmt.Fprintln(Stderr, err)
+		return 2
+	}
+	return 0
+}
+
+// load 未加载配置时加载
+func load(a *apijson.ApiJson) (err error) {
+	if a.Config().Snapshot() != nil {
+		return nil
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("load: %v", r)
+		}
+	}()
+	a.Load()
+	return nil
+}
+
+func openApi(ctx context.Context, a *apijson.ApiJson, args []string) int {
+	flags := newFlagSet("openapi")
+	output := flags.String("o", "", "输出文件, 为空时输出到 stdout")
+	var opts openapi.Options
+	flags.StringVar(&opts.Role, "role", "", "按该角色可访问的表与字段生成, 默认 UNKNOWN")
+	flags.StringVar(&opts.Title, "title", "", "文档标题")
+	flags.StringVar(&opts.Prefix, "prefix", "", "接口的前缀, 如 /api")
+	requestSchemas := flags.Bool("schemas", false, "导出 _request 的 JSON Schema, 而非 OpenAPI 文档")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	if err := load(a); err != nil {
+		fmt.Fprintln(Stderr, err)
+		return 2
+	}
+
+	var doc any
+	if *requestSchemas {
+		doc = openapi.RequestSchemas(ctx, a, opts.Role)
+	} else {
+		doc = openapi.Document(ctx, a, opts)
+	}
+
+	content, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		fmt.Fprintln(Stderr, err)
+		return 2
+	}
+
+	if *output == "" {
+		fmt.Fprintln(Stdout, string(content))
+		return 0
+	}
+
+	if err = os.WriteFile(*output, append(content, '\n'), 0644); err != nil {
+		fmt.Fprintln(Stderr, err)
+		return 2
+	}
+	return 0
+}
+
+func typeScript(ctx context.Context, a *apijson.ApiJson, args []string) int {
+	flags := newFlagSet("typescript")
+	output := flags.String("o", "", "输出文件, 如 apijson.ts, 为空时输出到 stdout")
+	roles := flags.String("roles", "", "生成的角色, 以逗号分隔, 默认为全部角色")
+	var opts openapi.Options
+	flags.StringVar(&opts.Prefix, "prefix", "", "接口的前缀, 如 /api")
+	flags.BoolVar(&opts.SpreadMode, "spread", false, "响应为 SpreadMode")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	if err := load(a); err != nil {
+		fmt.Fprintln(Stderr, err)
+		return 2
+	}
+
+	var roleList []string
+	if *roles != "" {
+		roleList = lo.Map(strings.Split(*roles, ","), func(role string, _ int) string { return strings.TrimSpace(role) })
+	}
+	content := openapi.TypeScript(ctx, a, opts, roleList)
+
+	if *output == "" {
+		fmt.Fprint(Stdout, content)
+		return 0
+	}
+
+	if err := os.WriteFile(*output, []byte(content), 0644); err != nil {
+		fmt.Fprintln(Stderr, err)
+		return 2
+	}
+	return 0
+}
@@ -0,0 +1,455 @@
+package client
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/glennliao/apijson-go/consts"
+	"github.com/glennliao/apijson-go/model"
+	"github.com/glennliao/apijson-go/util"
+)
+
+var (
+	fieldExp = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+	// 字段 (多个时以逗号分隔) 加可选的操作符, 如 id、id{}、id!{}、name$、userId@、count+、userId,roleId{}
+	keyExp  = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*(,[A-Za-z_][A-Za-z0-9_]*)*([&|!]?\{\}|\$|~|@|\(\)|\+|-)?$`)
+	ctrlExp = regexp.MustCompile(`^@[a-z]+$`)
+	// @column 中的一项, 如 id、name:alias、count(id):total
+	columnExp = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*(\([A-Za-z0-9_*]*\))?(:[A-Za-z_][A-Za-z0-9_]*)?$`)
+	orderExp  = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*[+-]?$`)
+)
+
+// Node 请求中的节点, 由 Table、List、Struct 等创建
+type Node interface {
+	// Key 节点在请求中的 key, 如 User、User[]、[]
+	Key() string
+	build() (any, error)
+}
+
+func keyErr(key string, format string, args ...any) error {
+	msg := fmt.Sprintf(format, args...)
+	if key = strings.TrimPrefix(key, "."); key != "" {
+		msg = key + ": " + msg
+	}
+	return consts.NewValidReqErr(msg)
+}
+
+func checkField(field string) error {
+	if !fieldExp.MatchString(field) {
+		return fmt.Errorf("invalid field %q", field)
+	}
+	return nil
+}
+
+// checkKey 检查节点中的 key 是否符合语法
+func checkKey(key string) error {
+	if ctrlExp.MatchString(key) || keyExp.MatchString(key) {
+		return nil
+	}
+	return fmt.Errorf("invalid key %q", key)
+}
+
+// buildNodes 子节点, key 不能重复
+func buildNodes(parent string, m model.Map, nodes []Node) error {
+	for _, node := range nodes {
+		key := node.Key()
+		if _, exists := m[key]; exists {
+			return keyErr(parent+key, "duplicate node")
+		}
+		v, err := node.build()
+		if err != nil {
+			return err
+		}
+		m[key] = v
+	}
+	return nil
+}
+
+// Request 请求, 由节点组成, Map 时检查所有节点
+type Request struct {
+	nodes   []Node
+	tag     string
+	version string
+}
+
+// NewRequest 创建请求, 如
+//
+//	client.NewRequest(
+//		client.Table("User").Eq("id", 1).Column("id", "username"),
+//		client.List("Todo").Ref("userId", "User/id").Order("id-").Count(10),
+//	)
+func NewRequest(nodes ...Node) *Request {
+	return &Request{nodes: nodes}
+}
+
+// Add 添加节点
+func (r *Request) Add(nodes ...Node) *Request {
+	r.nodes = append(r.nodes, nodes...)
+	return r
+}
+
+// Tag 写入请求的 tag, 对应 _request
+func (r *Request) Tag(tag string) *Request {
+	r.tag = tag
+	return r
+}
+
+// Version 写入请求的版本, 为空时使用最新版本
+func (r *Request) Version(version string) *Request {
+	r.version = version
+	return r
+}
+
+// Map 生成请求, key 不符合语法、重复或分页用在非列表节点时返回错误
+func (r *Request) Map() (model.Map, error) {
+	m := model.Map{}
+	if r.tag != "" {
+		m[consts.Tag] = r.tag
+	}
+	if r.version != "" {
+		m[consts.Version] = r.version
+	}
+	if err := buildNodes("", m, r.nodes); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// MustMap 同 Map, 出错时 panic, 用于固定的请求
+func (r *Request) MustMap() model.Map {
+	m, err := r.Map()
+	if err != nil {
+		panic(err)
+	}
+	return m
+}
+
+// TableNode 表节点, 包含查询条件、控制字段或写入的数据
+type TableNode struct {
+	key    string
+	isList bool
+	values model.Map
+	err    error
+}
+
+// Table 查询或写入单行, alias 为 _access 中的 alias, 如 User
+func Table(alias string) *TableNode {
+	t := &TableNode{key: alias, values: model.Map{}}
+	if !util.IsFirstUp(alias) || checkField(alias) != nil {
+		t.err = keyErr(alias, "table alias must be an identifier starting with upper case")
+	}
+	return t
+}
+
+// List 查询列表, 为 Alias[], 可使用 Page、Count、Total
+func List(alias string) *TableNode {
+	t := Table(alias)
+	t.key = alias + consts.ListKeySuffix
+	t.isList = true
+	return t
+}
+
+func (t *TableNode) Key() string {
+	return t.key
+}
+
+func (t *TableNode) build() (any, error) {
+	if t.err != nil {
+		return nil, t.err
+	}
+	return t.values, nil
+}
+
+func (t *TableNode) set(key string, val any) *TableNode {
+	if t.err != nil {
+		return t
+	}
+	if _, exists := t.values[key]; exists {
+		t.err = keyErr(t.key+"."+key, "duplicate key")
+		return t
+	}
+	t.values[key] = val
+	return t
+}
+
+func (t *TableNode) setField(field string, op string, val any) *TableNode {
+	if err := checkField(field); err != nil && t.err == nil {
+		t.err = keyErr(t.key, "%s", err)
+	}
+	return t.set(field+op, val)
+}
+
+// Set 使用原始的 key, 如 userId,roleId{}, 会检查语法
+func (t *TableNode) Set(key string, val any) *TableNode {
+	if err := checkKey(key); err != nil && t.err == nil {
+		t.err = keyErr(t.key, "%s", err)
+	}
+	return t.set(key, val)
+}
+
+// Eq 等于, 写入时为字段的值
+func (t *TableNode) Eq(field string, val any) *TableNode {
+	return t.setField(field, "", val)
+}
+
+// In 在列表中, field{}
+func (t *TableNode) In(field string, vals ...any) *TableNode {
+	return t.setField(field, consts.OpIn, vals)
+}
+
+// NotIn 不在列表中, field!{}
+func (t *TableNode) NotIn(field string, vals ...any) *TableNode {
+	return t.setField(field, "!"+consts.OpIn, vals)
+}
+
+// Range 比较条件, 需同时满足, 如 Range("id", ">=1", "<10") 为 id&{}
+func (t *TableNode) Range(field string, conditions ...string) *TableNode {
+	for _, c := range conditions {
+		if strings.Contains(c, ",") && t.err == nil {
+			t.err = keyErr(t.key+"."+field, "condition %q contains ','", c)
+		}
+	}
+	return t.setField(field, "&"+consts.OpIn, strings.Join(conditions, ","))
+}
+
+// Like 模糊搜索, 如 %name%
+func (t *TableNode) Like(field string, pattern string) *TableNode {
+	return t.setField(field, consts.OpLike, pattern)
+}
+
+// Regexp 正则搜索
+func (t *TableNode) Regexp(field string, pattern string) *TableNode {
+	return t.setField(field, consts.OpRegexp, pattern)
+}
+
+// Ref 引用其他节点的字段, 如 Ref("userId", "User/id"), / 开头时为同级节点
+func (t *TableNode) Ref(field string, path string) *TableNode {
+	if !strings.Contains(strings.TrimPrefix(path, "/"), "/") && t.err == nil {
+		t.err = keyErr(t.key+"."+field, "ref path %q must be node/field", path)
+	}
+	return t.setField(field, consts.RefKeySuffix, path)
+}
+
+// Func 字段的值由函数计算, 如 Func("name", "concat(firstName,lastName)")
+func (t *TableNode) Func(field string, expr string) *TableNode {
+	return t.setField(field, consts.FunctionsKeySuffix, expr)
+}
+
+// Incr 写入时字段增加 n, 为负数时减少
+func (t *TableNode) Incr(field string, n any) *TableNode {
+	return t.setField(field, consts.OpPLus, n)
+}
+
+func (t *TableNode) setFields(key string, exp *regexp.Regexp, fields []string) *TableNode {
+	for _, field := range fields {
+		if !exp.MatchString(field) && t.err == nil {
+			t.err = keyErr(t.key+"."+key, "invalid field %q", field)
+		}
+	}
+	return t.set(key, strings.Join(fields, ","))
+}
+
+// Column 返回的字段, 可使用别名与函数, 如 id、name:alias、count(id):total
+func (t *TableNode) Column(fields ...string) *TableNode {
+	return t.setFields(consts.Column, columnExp, fields)
+}
+
+// Order 排序, - 为降序, 如 Order("id-", "name")
+func (t *TableNode) Order(fields ...string) *TableNode {
+	return t.setFields("@order", orderExp, fields)
+}
+
+// Group 分组
+func (t *TableNode) Group(fields ...string) *TableNode {
+	return t.setFields("@group", fieldExp, fields)
+}
+
+// Return 写入后回查的字段
+func (t *TableNode) Return(fields ...string) *TableNode {
+	return t.setFields(consts.Return, fieldExp, fields)
+}
+
+// Role 访问该节点的角色
+func (t *TableNode) Role(role string) *TableNode {
+	return t.set(consts.Role, role)
+}
+
+// WithDeleted 包含软删除的行, 仅 ADMIN 可用
+func (t *TableNode) WithDeleted() *TableNode {
+	return t.set(consts.Deleted, true)
+}
+
+func (t *TableNode) listOnly(key string) {
+	if !t.isList && t.err == nil {
+		t.err = keyErr(t.key, "%s only for list", key)
+	}
+}
+
+// Page 页码, 从 0 开始
+func (t *TableNode) Page(page int) *TableNode {
+	t.listOnly(consts.Page)
+	return t.set(consts.Page, page)
+}
+
+// Count 每页数量
+func (t *TableNode) Count(count int) *TableNode {
+	t.listOnly(consts.Count)
+	return t.set(consts.Count, count)
+}
+
+// Total 同时查询总数, 通过 Ref("total", "User[]/total") 返回
+func (t *TableNode) Total() *TableNode {
+	t.listOnly(consts.Query)
+	return t.set(consts.Query, 2)
+}
+
+// StructNode 结构节点, 如 [], 子节点中的表节点均为列表
+type StructNode struct {
+	key      string
+	isList   bool
+	children []Node
+	values   model.Map
+	err      error
+}
+
+// Struct 结构节点, key 小写开头, 以 [] 结尾时为列表
+func Struct(key string, children ...Node) *StructNode {
+	s := &StructNode{key: key, children: children, values: model.Map{}}
+	s.isList = strings.HasSuffix(key, consts.ListKeySuffix)
+	if name := strings.TrimSuffix(key, consts.ListKeySuffix); name != "" && (util.IsFirstUp(name) || checkField(name) != nil) {
+		s.err = keyErr(key, "struct key must be an identifier starting with lower case")
+	}
+	return s
+}
+
+// ListOf 列表节点 [], 需有一个表节点作为主表, 其他表节点通过 Ref 关联
+func ListOf(children ...Node) *StructNode {
+	return Struct(consts.ListKeySuffix, children...)
+}
+
+func (s *StructNode) Key() string {
+	return s.key
+}
+
+// Add 添加子节点
+func (s *StructNode) Add(children ...Node) *StructNode {
+	s.children = append(s.children, children...)
+	return s
+}
+
+func (s *StructNode) set(key string, val any) *StructNode {
+	if !s.isList && s.err == nil {
+		s.err = keyErr(s.key, "%s only for list", key)
+	}
+	s.values[key] = val
+	return s
+}
+
+// Page 页码, 从 0 开始
+func (s *StructNode) Page(page int) *StructNode {
+	return s.set(consts.Page, page)
+}
+
+// Count 每页数量
+func (s *StructNode) Count(count int) *StructNode {
+	return s.set(consts.Count, count)
+}
+
+// Total 同时查询总数, 通过 Ref("total", "[]/total") 返回
+func (s *StructNode) Total() *StructNode {
+	return s.set(consts.Query, 2)
+}
+
+func (s *StructNode) build() (any, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+
+	m := model.Map{}
+	for k, v := range s.values {
+		m[k] = v
+	}
+	if err := buildNodes(s.key+".", m, s.children); err != nil {
+		return nil, err
+	}
+
+	if s.key == consts.ListKeySuffix {
+		hasTable := false
+		for _, child := range s.children {
+			if _, ok := child.(*TableNode); ok {
+				hasTable = true
+			}
+		}
+		if !hasTable {
+			return nil, keyErr(s.key, "list must have a table node")
+		}
+	}
+	return m, nil
+}
+
+type valueNode struct {
+	key string
+	val any
+	err error
+}
+
+func (v *valueNode) Key() string {
+	return v.key
+}
+
+func (v *valueNode) build() (any, error) {
+	return v.val, v.err
+}
+
+func newValueNode(key string, suffix string, val any) *valueNode {
+	v := &valueNode{key: key + suffix, val: val}
+	if util.IsFirstUp(key) || checkField(key) != nil {
+		v.err = keyErr(v.key, "key must be an identifier starting with lower case")
+	}
+	return v
+}
+
+// Ref 引用节点, 如 Ref("total", "[]/total") 为 total@
+func Ref(key string, path string) Node {
+	return newValueNode(key, consts.RefKeySuffix, path)
+}
+
+// Func 函数节点, 如 Func("hello", "sayHello(User/username)") 为 hello()
+func Func(key string, expr string) Node {
+	return newValueNode(key, consts.FunctionsKeySuffix, expr)
+}
+
+type rowsNode struct {
+	key  string
+	rows []*TableNode
+}
+
+// Row 批量写入中的一行, 用于 Rows
+func Row() *TableNode {
+	return &TableNode{values: model.Map{}}
+}
+
+// Rows 批量写入, 为 Alias[], 如 Rows("User", Row().Eq("username", "a"), Row().Eq("username", "b"))
+func Rows(alias string, rows ...*TableNode) Node {
+	return &rowsNode{key: alias + consts.ListKeySuffix, rows: rows}
+}
+
+func (r *rowsNode) Key() string {
+	return r.key
+}
+
+func (r *rowsNode) build() (any, error) {
+	if alias := strings.TrimSuffix(r.key, consts.ListKeySuffix); !util.IsFirstUp(alias) || checkField(alias) != nil {
+		return nil, keyErr(r.key, "table alias must be an identifier starting with upper case")
+	}
+
+	list := make([]model.Map, len(r.rows))
+	for i, row := range r.rows {
+		if row.err != nil {
+			return nil, keyErr(fmt.Sprintf("%s.%d", r.key, i), "%s", row.err)
+		}
+		list[i] = row.values
+	}
+	return list, nil
+}
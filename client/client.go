@@ -0,0 +1,125 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/glennliao/apijson-go/consts"
+	"github.com/glennliao/apijson-go/model"
+)
+
+// Client 调用远程 apijson 服务的 HTTP 客户端, 接口为 web 驱动中的 /get、/post 等
+type Client struct {
+	// 服务地址, 如 http://localhost:8080/api
+	BaseUrl string
+	// 为 nil 时使用 http.DefaultClient
+	HttpClient *http.Client
+	// 每个请求附加的请求头, 如 Authorization
+	Header http.Header
+	// 服务端响应为 web.SpreadMode, 默认为 web.InDataMode
+	SpreadMode bool
+}
+
+func NewClient(baseUrl string) *Client {
+	return &Client{BaseUrl: strings.TrimSuffix(baseUrl, "/"), Header: http.Header{}}
+}
+
+// Get 查询
+func (c *Client) Get(ctx context.Context, r *Request) (model.Map, error) {
+	return c.Send(ctx, http.MethodGet, r)
+}
+
+// Post 新增, 需设置 Request.Tag
+func (c *Client) Post(ctx context.Context, r *Request) (model.Map, error) {
+	return c.Send(ctx, http.MethodPost, r)
+}
+
+// Put 修改, 需设置 Request.Tag
+func (c *Client) Put(ctx context.Context, r *Request) (model.Map, error) {
+	return c.Send(ctx, http.MethodPut, r)
+}
+
+// Delete 删除, 需设置 Request.Tag
+func (c *Client) Delete(ctx context.Context, r *Request) (model.Map, error) {
+	return c.Send(ctx, http.MethodDelete, r)
+}
+
+// Upsert 不存在时新增, 存在时修改, 需设置 Request.Tag
+func (c *Client) Upsert(ctx context.Context, r *Request) (model.Map, error) {
+	return c.Send(ctx, consts.MethodUpsert, r)
+}
+
+// Send 检查并发送请求
+func (c *Client) Send(ctx context.Context, method string, r *Request) (model.Map, error) {
+	req, err := r.Map()
+	if err != nil {
+		return nil, err
+	}
+	return c.Do(ctx, method, req)
+}
+
+// Do 发送请求, 返回响应中的 data; 数字为 json.Number
+// 响应中 ok 为 false 时返回 consts.Err, 其 Code 为响应中的 code
+func (c *Client) Do(ctx context.Context, method string, req model.Map) (model.Map, error) {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, c.BaseUrl+"/"+strings.ToLower(method), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range c.Header {
+		httpReq.Header[k] = v
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	httpClient := c.HttpClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+
+	httpRes, err := httpClient.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	defer httpRes.Body.Close()
+
+	content, err := io.ReadAll(httpRes.Body)
+	if err != nil {
+		return nil, err
+	}
+	if httpRes.StatusCode != http.StatusOK {
+		return nil, consts.NewErr(httpRes.StatusCode, fmt.Sprintf("%s: %s", httpRes.Status, bytes.TrimSpace(content)))
+	}
+
+	var res model.Map
+	dec := json.NewDecoder(bytes.NewReader(content))
+	dec.UseNumber()
+	if err = dec.Decode(&res); err != nil {
+		return nil, fmt.Errorf("decode response: %w", err)
+	}
+
+	if ok, _ := res["ok"].(bool); !ok {
+		code, _ := res["code"].(json.Number)
+		n, _ := code.Int64()
+		msg, _ := res["msg"].(string)
+		return nil, consts.NewErr(int(n), msg)
+	}
+
+	if c.SpreadMode {
+		for _, k := range []string{"ok", "code", "msg", "span"} {
+			delete(res, k)
+		}
+		return res, nil
+	}
+
+	data, _ := res["data"].(map[string]any)
+	return model.Map(data), nil
+}
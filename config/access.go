@@ -2,8 +2,13 @@ package config
 
 import (
 	"context"
+	"fmt"
+	"sort"
+	"strings"
+
 	"github.com/glennliao/apijson-go/consts"
 	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/os/gtime"
 	"github.com/samber/lo"
 )
 
@@ -18,6 +23,7 @@ type ConditionReq struct {
 type ConditionRet struct {
 	condition    map[string]any
 	rawCondition map[string]any
+	rawAnd       []map[string]any
 }
 
 func NewConditionRet() *ConditionRet {
@@ -32,14 +38,23 @@ func (c *ConditionRet) Add(k string, v any) {
 	c.condition[k] = v
 }
 
+// AddRaw 添加原始条件, k、v 同 gdb 的 Where(map); 多次添加的条件之间为 AND, 同一字段的条件不会相互覆盖
 func (c *ConditionRet) AddRaw(k string, v any) {
+	if _, exists := c.rawCondition[k]; exists {
+		c.rawAnd = append(c.rawAnd, map[string]any{k: v})
+		return
+	}
 	c.rawCondition[k] = v
 }
 
+// Where 条件, 原始条件为 consts.Raw 中的 map[string]any, 同一字段重复添加的在 consts.RawAnd 中
 func (c *ConditionRet) Where() map[string]any {
 	if len(c.rawCondition) > 0 {
 		c.condition[consts.Raw] = c.rawCondition
 	}
+	if len(c.rawAnd) > 0 {
+		c.condition[consts.RawAnd] = c.rawAnd
+	}
 	return c.condition
 }
 
@@ -53,10 +68,20 @@ type RoleReq struct {
 
 type DefaultRole func(ctx context.Context, req RoleReq) (string, error)
 
+// TenantId 获取上下文中当前请求的租户id
+type TenantId func(ctx context.Context) (any, error)
+
+// CtxValue 获取上下文中的值, 如当前用户id, 用于配置中的 $ctx.key
+type CtxValue func(ctx context.Context, key string) (any, error)
+
 func defaultRole(ctx context.Context, req RoleReq) (string, error) {
 	return consts.UNKNOWN, nil
 }
 
+func defaultCtxValue(ctx context.Context, key string) (any, error) {
+	return nil, nil
+}
+
 func defaultCondition(ctx context.Context, req ConditionReq, condition *ConditionRet) error {
 	return nil
 }
@@ -71,9 +96,29 @@ type Access struct {
 	// nodeRole 为前端显式指定的role, 需要此函数中判断该role是不是用户角色之一, 返回最终该节点的角色
 	DefaultRoleFunc DefaultRole
 
+	// 获取上下文中的值, 如当前用户id
+	CtxValueFunc CtxValue
+
+	// 获取当前请求的租户id, 用于配置了 TenantColumn 的表
+	TenantIdFunc TenantId
+
 	roleList []string
 
+	roleInherits  map[string][]string // 角色 -> 直接继承的角色
+	roleAncestors map[string][]string // ReLoad 时计算, 角色 -> 自身及继承的所有角色
+
 	accessConfigMap map[string]AccessConfig
+
+	// 快照中为 Config.Access, 函数与 NoVerify 从中读取, Load 之后的设置同样生效
+	live *Access
+}
+
+// hooks 读取函数与 NoVerify 的 Access
+func (a *Access) hooks() *Access {
+	if a.live != nil {
+		return a.live
+	}
+	return a
 }
 
 func NewAccess() *Access {
@@ -81,6 +126,7 @@ func NewAccess() *Access {
 	a := &Access{}
 	a.ConditionFunc = defaultCondition
 	a.DefaultRoleFunc = defaultRole
+	a.CtxValueFunc = defaultCtxValue
 	a.roleList = []string{consts.UNKNOWN, consts.LOGIN, consts.OWNER, consts.ADMIN}
 
 	return a
@@ -97,3 +143,126 @@ func (a *Access) AddRole(roles []string) *Access {
 }
 
 func (a *Access) RoleList() []string { return a.roleList }
+
+// roles 所有角色, 包括只在角色继承中出现的
+func (a *Access) roles() []string {
+	roles := append([]string{}, a.roleList...)
+	for role, inherits := range a.roleInherits {
+		roles = append(roles, role)
+		roles = append(roles, inherits...)
+	}
+	return lo.Uniq(roles)
+}
+
+// AliasList _access 中所有的 alias, 按名称排序
+func (a *Access) AliasList() []string {
+	aliasList := lo.Keys(a.accessConfigMap)
+	sort.Strings(aliasList)
+	return aliasList
+}
+
+// RoleInherit 设置角色继承, role 拥有 inherits 中角色的权限
+// 如 RoleInherit(consts.ADMIN, consts.OWNER) 后 _access 中允许 OWNER 的, ADMIN 同样允许
+func (a *Access) RoleInherit(role string, inherits ...string) *Access {
+	if a.roleInherits == nil {
+		a.roleInherits = map[string][]string{}
+	}
+	a.AddRole(append([]string{role}, inherits...))
+	a.roleInherits[role] = lo.Uniq(append(a.roleInherits[role], inherits...))
+	a.roleAncestors = nil
+	return a
+}
+
+// RoleWithInherits 角色自身及继承的所有角色, 按继承的远近排序
+func (a *Access) RoleWithInherits(role string) []string {
+	if roles, exists := a.roleAncestors[role]; exists {
+		return roles
+	}
+	return a.roleWithInherits(role)
+}
+
+func (a *Access) roleWithInherits(role string) []string {
+	roles := []string{role}
+	for i := 0; i < len(roles); i++ {
+		for _, inherit := range a.roleInherits[roles[i]] {
+			if !lo.Contains(roles, inherit) {
+				roles = append(roles, inherit)
+			}
+		}
+	}
+	return roles
+}
+
+// HasRole role 或其继承的角色是否在 roles 中
+func (a *Access) HasRole(roles []string, role string) bool {
+	for _, r := range a.RoleWithInherits(role) {
+		if lo.Contains(roles, r) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsRole role 是否为 target 或继承自 target
+func (a *Access) IsRole(role string, target string) bool {
+	return lo.Contains(a.RoleWithInherits(role), target)
+}
+
+// loadRoleInherits 检查角色继承是否有环, 并计算每个角色继承的所有角色
+func (a *Access) loadRoleInherits() error {
+	const (
+		visiting = 1
+		visited  = 2
+	)
+	state := map[string]int{}
+
+	var visit func(role string, path []string) error
+	visit = func(role string, path []string) error {
+		switch state[role] {
+		case visiting:
+			return fmt.Errorf("role inherit cycle: %s", strings.Join(append(path, role), " -> "))
+		case visited:
+			return nil
+		}
+		state[role] = visiting
+		for _, inherit := range a.roleInherits[role] {
+			if err := visit(inherit, append(path, role)); err != nil {
+				return err
+			}
+		}
+		state[role] = visited
+		return nil
+	}
+
+	roles := lo.Keys(a.roleInherits)
+	sort.Strings(roles)
+	for _, role := range roles {
+		if err := visit(role, nil); err != nil {
+			return err
+		}
+	}
+
+	ancestors := map[string][]string{}
+	for _, role := range a.roleList {
+		ancestors[role] = a.roleWithInherits(role)
+	}
+	a.roleAncestors = ancestors
+	return nil
+}
+
+// ResolveValue 解析配置中的值: $now 为当前时间, $ctx.key 为 CtxValueFunc 获取的值, 其他为常量
+func (a *Access) ResolveValue(ctx context.Context, val any) (any, error) {
+	str, ok := val.(string)
+	if !ok {
+		return val, nil
+	}
+
+	switch {
+	case str == consts.ValueNow:
+		return gtime.Now(), nil
+	case strings.HasPrefix(str, consts.CtxValuePrefix):
+		return a.hooks().CtxValueFunc(ctx, str[len(consts.CtxValuePrefix):])
+	}
+
+	return val, nil
+}
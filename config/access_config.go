@@ -1,10 +1,14 @@
 package config
 
 import (
+	"context"
 	"net/http"
+	"strings"
 
 	"github.com/glennliao/apijson-go/consts"
+	"github.com/glennliao/apijson-go/util"
 	"github.com/gogf/gf/v2/os/gtime"
+	"github.com/gogf/gf/v2/util/gconv"
 	"github.com/samber/lo"
 )
 
@@ -14,6 +18,12 @@ type FieldsGetValue struct {
 	MaxCount *int // 可使用的最大分页大小,默认100
 }
 
+// AutoFillValue 自动填充的值, 为 $now、$ctx.key 或常量, nil 为不填充
+type AutoFillValue struct {
+	Post any // 新增时的值
+	Put  any // 修改时的值
+}
+
 type AccessConfig struct {
 	Debug     int8
 	Name      string
@@ -29,32 +39,206 @@ type AccessConfig struct {
 	Detail    string
 
 	RowKeyGen string // 主键生成策略
-	RowKey    string
+	RowKey    string // 主键, 联合主键使用,分割 如 user_id,role_id
+	rowKeys   []string
 	FieldsGet map[string]*FieldsGetValue
 	Executor  string
+
+	// 乐观锁版本字段, put 时必须传入, 作为条件并自增
+	VersionColumn string
+
+	// 是否记录 put/delete 的审计日志, 需注册 action.AuditSink
+	Audit bool
+
+	// 软删除字段, 为NULL时表示未删除; delete 时设置为当前时间, get/put 时排除已删除的行
+	SoftDeleteColumn string
+
+	// 自动填充的字段, 如 created_at、updated_by, 不允许请求中传入
+	AutoFill map[string]*AutoFillValue
+
+	// 各角色可写入的字段, 未配置的角色使用 default, * 为全部字段; 均未配置时不限制
+	FieldsPost map[string][]string
+	FieldsPut  map[string][]string
+
+	// 行级权限策略, 角色 -> 请求方式(* 为全部) -> 条件(AND), 如 {"OWNER": {"*": ["user_id = $ctx.userId"]}}
+	RowPolicy map[string]map[string][]string
+	policies  map[string]map[string][]*rowPolicyCond
+
+	// 租户字段, 设置后自动按 Access.TenantIdFunc 获取的租户id 限制查询与写入, 不允许请求中传入
+	TenantColumn string
+
+	// 属性访问规则, 按顺序求值, 可拒绝访问或添加条件
+	Rules []*AccessRule
+	rules []*accessRule
+
+	// 配置来源, 如配置文件路径, 用于 Lint 时定位; 为空时使用 _access 与 alias
+	Source string `json:"-"`
+
+	access *Access
 }
 
-func (a *AccessConfig) GetFieldsGetOutByRole(role string) []string {
-	var fieldsMap map[string]string
+// RowKeys 主键字段列表, 单主键时只有一个
+func (a *AccessConfig) RowKeys() []string {
+	if a.rowKeys == nil && a.RowKey != "" {
+		return parseRowKey(a.RowKey)
+	}
+	return a.rowKeys
+}
 
-	if val, exists := a.FieldsGet[role]; exists {
-		fieldsMap = val.Out
-	} else {
-		fieldsMap = a.FieldsGet["default"].Out
+func parseRowKey(rowKey string) []string {
+	var rowKeys []string
+	for _, key := range strings.Split(rowKey, ",") {
+		if key = strings.TrimSpace(key); key != "" {
+			rowKeys = append(rowKeys, key)
+		}
 	}
-	return lo.Keys(fieldsMap)
+	return rowKeys
 }
 
-func (a *AccessConfig) GetFieldsGetInByRole(role string) map[string][]string {
-	var inFieldsMap map[string][]string
+// GetFieldsWriteByRole 角色在 post/put 时可写入的字段, limited 为 false 时不限制
+// upsert 时需同时满足 post 与 put 的限制
+func (a *AccessConfig) GetFieldsWriteByRole(method string, role string) (fields []string, limited bool) {
+	byRole := func(fieldsMap map[string][]string) ([]string, bool) {
+		for _, r := range a.Roles(role) {
+			if val, exists := fieldsMap[r]; exists {
+				return val, !lo.Contains(val, "*")
+			}
+		}
+		if val, exists := fieldsMap["default"]; exists {
+			return val, !lo.Contains(val, "*")
+		}
+		return nil, false
+	}
+
+	switch method {
+	case http.MethodPost:
+		return byRole(a.FieldsPost)
+	case http.MethodPut:
+		return byRole(a.FieldsPut)
+	case consts.MethodUpsert:
+		postFields, postLimited := byRole(a.FieldsPost)
+		putFields, putLimited := byRole(a.FieldsPut)
+		switch {
+		case postLimited && putLimited:
+			return lo.Intersect(postFields, putFields), true
+		case postLimited:
+			return postFields, true
+		case putLimited:
+			return putFields, true
+		}
+	}
+
+	return nil, false
+}
+
+// AccessConfigError _access 配置中字段的错误
+type AccessConfigError struct {
+	Field string // 字段, 如 RowPolicy、Rules
+	Err   error
+}
+
+func (e *AccessConfigError) Error() string {
+	return e.Field + ": " + e.Err.Error()
+}
+
+func (e *AccessConfigError) Unwrap() error {
+	return e.Err
+}
 
-	if val, exists := a.FieldsGet[role]; exists {
-		inFieldsMap = val.In
-	} else {
-		inFieldsMap = a.FieldsGet["default"].In
+// compile 解析主键、行级策略与访问规则, 错误为 *AccessConfigError
+func (a *AccessConfig) compile() error {
+	a.rowKeys = parseRowKey(a.RowKey)
+
+	policies, err := compileRowPolicy(a.RowPolicy)
+	if err != nil {
+		return &AccessConfigError{Field: "RowPolicy", Err: err}
+	}
+	a.policies = policies
+
+	rules, err := compileAccessRules(a.Rules)
+	if err != nil {
+		return &AccessConfigError{Field: "Rules", Err: err}
+	}
+	a.rules = rules
+
+	return nil
+}
+
+// CheckAccessConfig 检查 _access 配置, 与 Load 时的检查相同, 用于在加载前发现错误
+func CheckAccessConfig(access AccessConfig) error {
+	return access.compile()
+}
+
+// TenantId 当前请求的租户id, 未获取到时拒绝访问
+func (a *AccessConfig) TenantId(ctx context.Context) (any, error) {
+	var tenantId any
+	if a.access != nil && a.access.hooks().TenantIdFunc != nil {
+		var err error
+		tenantId, err = a.access.hooks().TenantIdFunc(ctx)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if tenantId == nil || gconv.String(tenantId) == "" {
+		return nil, consts.NewNoAccessErr(a.Alias+"."+a.TenantColumn, "no tenant")
+	}
+	return tenantId, nil
+}
+
+// CheckTenantKeys 请求中不允许设置或筛选租户字段, key 按 dbStyle 转换后比较
+func (a *AccessConfig) CheckTenantKeys(ctx context.Context, req map[string]any, dbStyle FieldStyle) error {
+	for key := range req {
+		if strings.HasPrefix(key, "@") || strings.HasSuffix(key, "()") {
+			continue
+		}
+		for _, field := range util.KeyFields(key) {
+			if dbStyle(ctx, a.Name, strings.TrimSpace(field)) == a.TenantColumn {
+				return consts.NewNoAccessErr(a.Alias+"."+field, "tenant")
+			}
+		}
+	}
+	return nil
+}
+
+// Roles 角色及其继承的角色, 按继承的远近排序
+func (a *AccessConfig) Roles(role string) []string {
+	if a.access == nil {
+		return []string{role}
 	}
+	return a.access.RoleWithInherits(role)
+}
+
+// HasRole role 或其继承的角色是否在 roles 中
+func (a *AccessConfig) HasRole(roles []string, role string) bool {
+	for _, r := range a.Roles(role) {
+		if lo.Contains(roles, r) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsRole role 是否为 target 或继承自 target
+func (a *AccessConfig) IsRole(role string, target string) bool {
+	return lo.Contains(a.Roles(role), target)
+}
 
-	return inFieldsMap
+// GetFieldsGetByRole 按角色及继承的角色查找 FieldsGet, 均未配置时使用 default
+func (a *AccessConfig) GetFieldsGetByRole(role string) *FieldsGetValue {
+	for _, r := range a.Roles(role) {
+		if val, exists := a.FieldsGet[r]; exists {
+			return val
+		}
+	}
+	return a.FieldsGet["default"]
+}
+
+func (a *AccessConfig) GetFieldsGetOutByRole(role string) []string {
+	return lo.Keys(a.GetFieldsGetByRole(role).Out)
+}
+
+func (a *AccessConfig) GetFieldsGetInByRole(role string) map[string][]string {
+	return a.GetFieldsGetByRole(role).In
 }
 
 func (a *Access) GetAccess(accessName string, noVerify bool) (*AccessConfig, error) {
@@ -63,14 +247,16 @@ func (a *Access) GetAccess(accessName string, noVerify bool) (*AccessConfig, err
 	if !ok {
 		if noVerify {
 			return &AccessConfig{
-				Debug: 0,
-				Name:  accessName,
-				Alias: accessName,
+				Debug:  0,
+				Name:   accessName,
+				Alias:  accessName,
+				access: a,
 			}, nil
 		}
 		return nil, consts.NewAccessNoFoundErr(accessName)
 	}
 
+	access.access = a
 	return &access, nil
 }
 
@@ -0,0 +1,135 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/glennliao/apijson-go/consts"
+	"github.com/samber/lo"
+)
+
+// AccessRule 属性访问规则, When 为 true 时生效: Deny 为拒绝访问, 否则将 Where 加入条件
+type AccessRule struct {
+	Methods []string // 适用的请求方式, 为空时适用全部
+	Roles   []string // 适用的角色, 为空时适用全部; 角色未出现在任何规则中时, 使用最近的继承的角色的规则
+	When    string   // 规则表达式, 见 rule_expr.go
+	Deny    bool
+	Where   []string // 条件格式同 RowPolicy, post 时不生效, upsert 时拒绝访问
+}
+
+type accessRule struct {
+	*AccessRule
+	when  ruleExpr
+	where []*rowPolicyCond
+}
+
+func compileAccessRules(rules []*AccessRule) ([]*accessRule, error) {
+	var compiled []*accessRule
+	for i, rule := range rules {
+		if rule == nil {
+			continue
+		}
+
+		when, err := compileRuleExpr(rule.When)
+		if err != nil {
+			return nil, fmt.Errorf("rule %d: %s: %w", i, rule.When, err)
+		}
+
+		// 复制后修改, 不影响调用方的配置
+		copied := *rule
+		copied.Methods = make([]string, len(rule.Methods))
+		for j, method := range rule.Methods {
+			copied.Methods[j] = strings.ToUpper(method)
+		}
+		r := &accessRule{AccessRule: &copied, when: when}
+		for _, cond := range rule.Where {
+			c, err := parseRowPolicyCond(cond)
+			if err != nil {
+				return nil, fmt.Errorf("rule %d: %w", i, err)
+			}
+			r.where = append(r.where, c)
+		}
+		if !rule.Deny && len(r.where) == 0 {
+			return nil, fmt.Errorf("rule %d: need Deny or Where", i)
+		}
+		compiled = append(compiled, r)
+	}
+	return compiled, nil
+}
+
+// ruleRole 规则中使用的角色: 角色自身或最近的出现在规则中的继承的角色
+// 通过继承获得访问权限的角色同样受被继承角色的规则限制
+func (a *AccessConfig) ruleRole(role string) string {
+	for _, r := range a.Roles(role) {
+		for _, rule := range a.rules {
+			if lo.Contains(rule.Roles, r) {
+				return r
+			}
+		}
+	}
+	return role
+}
+
+// EvalRules 按顺序求值 _access 中的规则, 命中 Deny 的规则时拒绝访问, 命中其他规则时将条件加入 condition
+// req 为节点的请求数据, 在表达式中为 req.field
+func (a *AccessConfig) EvalRules(ctx context.Context, method string, role string, req map[string]any, condition *ConditionRet) error {
+	if len(a.rules) == 0 {
+		return nil
+	}
+
+	env := &ruleEnv{
+		ctx:       ctx,
+		principal: defaultCtxValue,
+		vars: map[string]any{
+			"req":    req,
+			"method": method,
+			"role":   role,
+			"access": a.Alias,
+		},
+		now: time.Now(),
+	}
+	if a.access != nil && a.access.hooks().CtxValueFunc != nil {
+		env.principal = a.access.hooks().CtxValueFunc
+	}
+
+	ruleRole := a.ruleRole(role)
+	for _, rule := range a.rules {
+		if len(rule.Methods) > 0 && !lo.Contains(rule.Methods, method) {
+			continue
+		}
+		if len(rule.Roles) > 0 && !lo.Contains(rule.Roles, ruleRole) {
+			continue
+		}
+
+		v, err := rule.when.eval(env)
+		if err == nil {
+			v, err = ruleBool(v)
+		}
+		if err != nil {
+			return consts.NewSysErr(a.Alias + " rule " + rule.When + ": " + err.Error())
+		}
+		if !v.(bool) {
+			continue
+		}
+
+		if rule.Deny {
+			return consts.NewDenyErr(a.Alias, role)
+		}
+
+		switch method {
+		case http.MethodPost:
+		case consts.MethodUpsert:
+			return consts.NewNoAccessErr(a.Alias+"."+consts.MethodUpsert, role)
+		default:
+			err = a.addPolicyWhere(ctx, rule.where, condition)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
@@ -9,17 +9,22 @@ import (
 type ActionConfig struct {
 	requestConfig    *RequestConfigs
 	access           *Access
+	dbMeta           *DBMeta
 	functions        *functions
 	rowKeyGenFuncMap map[string]RowKeyGenFuncHandler
-	defaultRoleFunc  DefaultRole
 }
 
 func (c *ActionConfig) NoVerify() bool {
-	return c.access.NoVerify
+	return c.access.hooks().NoVerify
+}
+
+// DbMeta 表结构, 未设置 DbMetaProvider 时可能为 nil
+func (c *ActionConfig) DbMeta() *DBMeta {
+	return c.dbMeta
 }
 
 func (c *ActionConfig) DefaultRoleFunc() DefaultRole {
-	return c.defaultRoleFunc
+	return c.access.hooks().DefaultRoleFunc
 }
 
 func (c *ActionConfig) GetAccessConfig(key string, noVerify bool) (*AccessConfig, error) {
@@ -35,7 +40,20 @@ func (c *ActionConfig) GetRequest(tag string, method string, version string) (*R
 }
 
 func (c *ActionConfig) ConditionFunc(ctx context.Context, req ConditionReq, condition *ConditionRet) error {
-	return c.access.ConditionFunc(ctx, req, condition)
+	return c.access.hooks().ConditionFunc(ctx, req, condition)
+}
+
+// HasRole role 或其继承的角色是否在 roles 中
+func (c *ActionConfig) HasRole(roles []string, role string) bool {
+	return c.access.HasRole(roles, role)
+}
+
+func (c *ActionConfig) IsRole(role string, target string) bool {
+	return c.access.IsRole(role, target)
+}
+
+func (c *ActionConfig) ResolveValue(ctx context.Context, val any) (any, error) {
+	return c.access.ResolveValue(ctx, val)
 }
 
 func (c *ActionConfig) RowKeyGen(ctx context.Context, genFuncName string, accessName string, data model.Map) (model.Map, error) {
@@ -1,6 +1,15 @@
 package config
 
-import "context"
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/glennliao/apijson-go/util"
+)
 
 type AccessListProvider func(ctx context.Context) []AccessConfig
 
@@ -26,6 +35,40 @@ func RegDbMetaProvider(name string, provider DbMetaProvider) {
 	dbMetaProviderMap[name] = provider
 }
 
+// RegAccessListProvider 在实例中注册 provider, 同名时优先于全局注册的
+func (c *Config) RegAccessListProvider(name string, provider AccessListProvider) {
+	c.accessListProviderMap[name] = provider
+}
+
+func (c *Config) RegRequestListProvider(name string, provider RequestListProvider) {
+	c.requestListProviderMap[name] = provider
+}
+
+func (c *Config) RegDbMetaProvider(name string, provider DbMetaProvider) {
+	c.dbMetaProviderMap[name] = provider
+}
+
+func (c *Config) accessListProvider() AccessListProvider {
+	if provider, exists := c.accessListProviderMap[c.AccessListProvider]; exists {
+		return provider
+	}
+	return accessListProviderMap[c.AccessListProvider]
+}
+
+func (c *Config) requestListProvider() RequestListProvider {
+	if provider, exists := c.requestListProviderMap[c.RequestListProvider]; exists {
+		return provider
+	}
+	return requestListProviderMap[c.RequestListProvider]
+}
+
+func (c *Config) dbMetaProvider() DbMetaProvider {
+	if provider, exists := c.dbMetaProviderMap[c.DbMetaProvider]; exists {
+		return provider
+	}
+	return dbMetaProviderMap[c.DbMetaProvider]
+}
+
 type Config struct {
 	Access *Access
 
@@ -36,23 +79,33 @@ type Config struct {
 
 	rowKeyGenFuncMap map[string]RowKeyGenFuncHandler
 
+	// SnowflakeNode 内置 snowflake 主键生成的节点号(0-1023), 同时运行的实例需不同; 默认 -1 未设置, 使用时返回错误
+	SnowflakeNode int64
+
+	// ShortIdPrefix 内置 shortid 主键生成的前缀, access alias -> 前缀; ShortIdLength 为随机部分的长度, 默认 12
+	ShortIdPrefix map[string]string
+	ShortIdLength int
+
 	// dbFieldStyle 数据库字段命名风格 请求传递到数据库中
 	DbFieldStyle FieldStyle
 
 	// jsonFieldStyle 数据库返回的字段
 	JsonFieldStyle FieldStyle
 
+	// DbMeta 未设置 DbMetaProvider 时使用, 加载后请使用 Snapshot().DbMeta()
 	DbMeta *DBMeta
 
 	AccessListProvider  string
 	RequestListProvider string
 	DbMetaProvider      string
 
-	accessList []AccessConfig
+	// 实例中注册的 provider, 未注册的使用全局注册的
+	accessListProviderMap  map[string]AccessListProvider
+	requestListProviderMap map[string]RequestListProvider
+	dbMetaProviderMap      map[string]DbMetaProvider
 
-	requestConfigs *RequestConfigs
-	queryConfig    *QueryConfig
-	actionConfig   *ActionConfig
+	snapshot   atomic.Value // *Snapshot
+	reloadLock sync.Mutex
 }
 
 func New() *Config {
@@ -62,10 +115,17 @@ func New() *Config {
 	a.RequestListProvider = "db"
 	a.DbMetaProvider = "db"
 
+	a.accessListProviderMap = make(map[string]AccessListProvider)
+	a.requestListProviderMap = make(map[string]RequestListProvider)
+	a.dbMetaProviderMap = make(map[string]DbMetaProvider)
+
 	a.MaxTreeWidth = 5
 	a.MaxTreeDeep = 5
 
+	a.SnowflakeNode = -1
+	a.ShortIdLength = 12
 	a.rowKeyGenFuncMap = make(map[string]RowKeyGenFuncHandler)
+	regBuiltinRowKeyGen(a)
 
 	a.DbFieldStyle = CaseSnake
 	a.JsonFieldStyle = CaseCamel
@@ -76,20 +136,65 @@ func New() *Config {
 	return a
 }
 
+// ReLoad 加载配置, 失败时 panic, 用于启动时
 func (c *Config) ReLoad() {
+	if err := c.Reload(context.Background()); err != nil {
+		panic(err)
+	}
+}
 
-	accessConfigMap := make(map[string]AccessConfig)
+// Reload 重新加载配置, 生成新的快照后原子替换; 进行中的请求继续使用原快照
+// 加载失败时返回错误, 保留原快照
+func (c *Config) Reload(ctx context.Context) (err error) {
+	c.reloadLock.Lock()
+	defer c.reloadLock.Unlock()
+
+	defer util.RecoverErr(&err, "reload config")
 
-	ctx := context.Background()
+	snapshot, err := c.load(ctx)
+	if err != nil {
+		return err
+	}
+
+	c.snapshot.Store(snapshot)
+	return nil
+}
 
-	accessListProvider := accessListProviderMap[c.AccessListProvider]
+func (c *Config) load(ctx context.Context) (*Snapshot, error) {
+
+	prev := c.Snapshot()
+
+	s := &Snapshot{
+		access:   c.Access.clone(),
+		dbMeta:   c.DbMeta,
+		LoadedAt: time.Now(),
+	}
+
+	if prev != nil {
+		s.requestConfigs = prev.requestConfigs
+		s.requestList = prev.requestList
+		// 未设置 DbMeta 时沿用上次加载的
+		if s.dbMeta == nil {
+			s.dbMeta = prev.dbMeta
+		}
+	}
+
+	// 表结构先于 _access 加载, 用于推断主键
+	dbMetaProvider := c.dbMetaProvider()
+	if dbMetaProvider != nil {
+		s.dbMeta = NewDbMeta(dbMetaProvider(ctx))
+	}
+
+	accessConfigMap := make(map[string]AccessConfig)
+
+	accessListProvider := c.accessListProvider()
 
 	if accessListProvider != nil {
-		c.accessList = accessListProvider(ctx)
+		s.accessList = accessListProvider(ctx)
 
 		defaultMaxCount := 100
 
-		for _, access := range c.accessList {
+		for _, access := range s.accessList {
 			name := access.Alias
 			if name == "" {
 				name = access.Name
@@ -108,44 +213,67 @@ func (c *Config) ReLoad() {
 					access.FieldsGet[role].MaxCount = &defaultMaxCount
 				}
 			}
+			// 未配置主键时使用表的主键
+			if access.RowKey == "" {
+				if table := s.dbMeta.GetTable(access.Name); table != nil {
+					access.RowKey = strings.Join(table.PrimaryKey, ",")
+				}
+			}
+
+			if err := access.compile(); err != nil {
+				return nil, fmt.Errorf("_access %s %w", name, err)
+			}
+
 			accessConfigMap[access.Alias] = access
 		}
 	}
 
-	c.Access.accessConfigMap = accessConfigMap
+	s.access.accessConfigMap = accessConfigMap
 
-	requestListProvider := requestListProviderMap[c.RequestListProvider]
-	if requestListProvider != nil {
-		requestList := requestListProvider(ctx)
-		c.requestConfigs = NewRequestConfig(requestList)
+	if err := s.access.loadRoleInherits(); err != nil {
+		return nil, err
 	}
 
-	dbMetaProvider := dbMetaProviderMap[c.DbMetaProvider]
-	if dbMetaProvider != nil {
-		c.DbMeta = NewDbMeta(dbMetaProvider(ctx))
+	requestListProvider := c.requestListProvider()
+	if requestListProvider != nil {
+		s.requestList = requestListProvider(ctx)
+		s.requestConfigs = NewRequestConfig(s.requestList)
 	}
 
-	c.queryConfig = &QueryConfig{
-		access:          c.Access,
-		functions:       c.Functions,
-		maxTreeDeep:     c.MaxTreeDeep,
-		maxTreeWidth:    c.MaxTreeWidth,
-		defaultRoleFunc: c.Access.DefaultRoleFunc,
+	s.queryConfig = &QueryConfig{
+		access:       s.access,
+		functions:    c.Functions,
+		maxTreeDeep:  c.MaxTreeDeep,
+		maxTreeWidth: c.MaxTreeWidth,
 	}
 
-	c.actionConfig = &ActionConfig{
-		requestConfig:    c.requestConfigs,
-		access:           c.Access,
+	s.actionConfig = &ActionConfig{
+		requestConfig:    s.requestConfigs,
+		access:           s.access,
+		dbMeta:           s.dbMeta,
 		functions:        c.Functions,
 		rowKeyGenFuncMap: c.rowKeyGenFuncMap,
-		defaultRoleFunc:  c.Access.DefaultRoleFunc,
 	}
+
+	return s, nil
+}
+
+// Snapshot 当前的配置快照, 未加载时为 nil
+func (c *Config) Snapshot() *Snapshot {
+	s, _ := c.snapshot.Load().(*Snapshot)
+	return s
 }
 
 func (c *Config) QueryConfig() *QueryConfig {
-	return c.queryConfig
+	if s := c.Snapshot(); s != nil {
+		return s.queryConfig
+	}
+	return nil
 }
 
 func (c *Config) ActionConfig() *ActionConfig {
-	return c.actionConfig
+	if s := c.Snapshot(); s != nil {
+		return s.actionConfig
+	}
+	return nil
 }
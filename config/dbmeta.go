@@ -1,20 +1,278 @@
 package config
 
-import "github.com/samber/lo"
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/glennliao/apijson-go/consts"
+	"github.com/glennliao/apijson-go/util"
+	"github.com/gogf/gf/v2/os/gtime"
+	"github.com/samber/lo"
+)
 
 type (
 	Column struct {
 		// 字段名
 		Name string
+		// 数据库中的类型, 如 bigint unsigned、varchar(32)
+		Type          string
+		Nullable      bool
+		Default       any
+		AutoIncrement bool
+		Comment       string
+	}
+
+	// Index 索引, 不包含主键
+	Index struct {
+		Name    string
+		Columns []string
+		Unique  bool
 	}
 
 	Table struct {
 		// 表名
 		Name    string
+		Comment string
 		Columns []Column
+		// 主键字段, 联合主键时有多个
+		PrimaryKey []string
+		Indexes    []Index
 	}
 )
 
+// 字段值的类别, 由数据库类型得出, 用于转换请求中的值
+const (
+	KindString  = "string"
+	KindInt     = "int"
+	KindFloat   = "float"
+	KindDecimal = "decimal"
+	KindBool    = "bool"
+	KindTime    = "time"
+	KindJson    = "json"
+	KindBytes   = "bytes"
+)
+
+var kindTypes = map[string][]string{
+	KindInt:     {"int", "integer", "tinyint", "smallint", "mediumint", "bigint", "int2", "int4", "int8", "serial", "smallserial", "bigserial"},
+	KindFloat:   {"float", "double", "real", "float4", "float8"},
+	KindDecimal: {"decimal", "numeric", "dec", "money"},
+	KindBool:    {"bool", "boolean"},
+	KindTime:    {"date", "datetime", "timestamp", "timestamptz", "time", "timetz", "year"},
+	KindJson:    {"json", "jsonb"},
+	KindBytes:   {"blob", "tinyblob", "mediumblob", "longblob", "binary", "varbinary", "bytea", "bit"},
+}
+
+// Kind 字段值的类别, 类型为空时 (如 sqlite 中未声明类型) 为空
+func (c *Column) Kind() string {
+	typ := strings.ToLower(strings.TrimSpace(c.Type))
+	if typ == "" {
+		return ""
+	}
+	if i := strings.IndexAny(typ, "( "); i > 0 {
+		typ = typ[:i]
+	}
+	for kind, types := range kindTypes {
+		if lo.Contains(types, typ) {
+			return kind
+		}
+	}
+	return KindString
+}
+
+// Unsigned 是否为无符号的整数
+func (c *Column) Unsigned() bool {
+	return c.Kind() == KindInt && strings.Contains(strings.ToLower(c.Type), "unsigned")
+}
+
+// Coerce 将请求中的值转换为字段的类型, 如 int 字段的 "1" 转换为 1, 无法转换时返回错误
+// nil、未知类别的值不转换
+func (c *Column) Coerce(val any) (any, error) {
+	if val == nil {
+		return nil, nil
+	}
+
+	switch c.Kind() {
+	case KindInt:
+		return c.coerceInt(val)
+
+	case KindFloat:
+		switch v := val.(type) {
+		case float64, float32:
+			return v, nil
+		case string, json.Number, int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+			f, err := strconv.ParseFloat(strings.TrimSpace(fmt.Sprint(v)), 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid number %v", v)
+			}
+			return f, nil
+		}
+
+	case KindDecimal:
+		// 保留字符串, 避免精度丢失
+		switch v := val.(type) {
+		case float64, float32, int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+			return v, nil
+		case string, json.Number:
+			s := strings.TrimSpace(fmt.Sprint(v))
+			if _, err := strconv.ParseFloat(s, 64); err != nil {
+				return nil, fmt.Errorf("invalid number %v", v)
+			}
+			return s, nil
+		}
+
+	case KindBool:
+		switch v := val.(type) {
+		case bool:
+			return v, nil
+		case string, json.Number, int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float64, float32:
+			switch strings.ToLower(strings.TrimSpace(fmt.Sprint(v))) {
+			case "true", "1":
+				return true, nil
+			case "false", "0":
+				return false, nil
+			}
+			return nil, fmt.Errorf("invalid bool %v", v)
+		}
+
+	case KindString:
+		switch v := val.(type) {
+		case string:
+			return v, nil
+		case float64:
+			return strconv.FormatFloat(v, 'f', -1, 64), nil
+		case float32:
+			return strconv.FormatFloat(float64(v), 'f', -1, 32), nil
+		case json.Number, int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, bool:
+			return fmt.Sprint(v), nil
+		}
+
+	case KindTime:
+		switch v := val.(type) {
+		case time.Time, *time.Time, gtime.Time, *gtime.Time:
+			return v, nil
+		case string:
+			if _, err := gtime.StrToTime(v); err != nil {
+				return nil, fmt.Errorf("invalid time %s", v)
+			}
+			return v, nil
+		}
+
+	default:
+		return val, nil
+	}
+
+	return nil, fmt.Errorf("expect %s, got %T", c.Kind(), val)
+}
+
+func (c *Column) coerceInt(val any) (any, error) {
+	switch v := val.(type) {
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		return v, nil
+	case bool:
+		// 如 mysql 中 tinyint(1) 的布尔值
+		if v {
+			return 1, nil
+		}
+		return 0, nil
+	case float64:
+		if v != math.Trunc(v) {
+			return nil, fmt.Errorf("invalid integer %v", v)
+		}
+		return int64(v), nil
+	case float32:
+		return c.coerceInt(float64(v))
+	case string, json.Number:
+		s := strings.TrimSpace(fmt.Sprint(v))
+		if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+			return n, nil
+		}
+		if n, err := strconv.ParseUint(s, 10, 64); err == nil && c.Unsigned() {
+			return n, nil
+		}
+		return nil, fmt.Errorf("invalid integer %v", v)
+	}
+	return nil, fmt.Errorf("expect %s, got %T", KindInt, val)
+}
+
+// Column 表中的字段, 不存在时为 nil
+func (t *Table) Column(name string) *Column {
+	for i := range t.Columns {
+		if t.Columns[i].Name == name {
+			return &t.Columns[i]
+		}
+	}
+	return nil
+}
+
+// UniqueKeys 主键与唯一索引的字段
+func (t *Table) UniqueKeys() [][]string {
+	var keys [][]string
+	if len(t.PrimaryKey) > 0 {
+		keys = append(keys, t.PrimaryKey)
+	}
+	for _, index := range t.Indexes {
+		if index.Unique {
+			keys = append(keys, index.Columns)
+		}
+	}
+	return keys
+}
+
+// CoerceReq 检查请求中 key 的字段是否存在, 并将值转换为字段的类型
+// key 为数据库风格, 可带操作符, 如 id{}、count+、user_id@; 引用、模糊搜索与 {} 中的条件表达式不转换
+func (t *Table) CoerceReq(key string, val any) (any, error) {
+	fields := util.KeyFields(key)
+	for _, field := range fields {
+		if t.Column(strings.TrimSpace(field)) == nil {
+			return nil, fmt.Errorf("不存在的字段 %s", strings.TrimSpace(field))
+		}
+	}
+
+	if len(fields) != 1 || val == nil {
+		return val, nil
+	}
+
+	switch {
+	case strings.HasSuffix(key, consts.RefKeySuffix),
+		strings.HasSuffix(key, consts.OpLike),
+		strings.HasSuffix(key, consts.OpRegexp):
+		return val, nil
+	}
+
+	column := t.Column(fields[0])
+
+	coerce := func(val any) (any, error) {
+		ret, err := column.Coerce(val)
+		if err != nil {
+			return nil, fmt.Errorf("%s 类型错误: %w", column.Name, err)
+		}
+		return ret, nil
+	}
+
+	if strings.HasSuffix(key, consts.OpIn) {
+		list, ok := val.([]any)
+		if !ok {
+			return val, nil
+		}
+		ret := make([]any, len(list))
+		for i, item := range list {
+			v, err := coerce(item)
+			if err != nil {
+				return nil, err
+			}
+			ret[i] = v
+		}
+		return ret, nil
+	}
+
+	return coerce(val)
+}
+
 type DBMeta struct {
 	tableMap map[string]Table
 }
@@ -30,6 +288,18 @@ func NewDbMeta(tables []Table) *DBMeta {
 	return d
 }
 
+// GetTable 表结构, 不存在时为 nil
+func (d *DBMeta) GetTable(tableName string) *Table {
+	if d == nil {
+		return nil
+	}
+	table, exists := d.tableMap[tableName]
+	if !exists {
+		return nil
+	}
+	return &table
+}
+
 func (d *DBMeta) GetTableColumns(tableName string) (columns []string) {
 	for _, column := range d.tableMap[tableName].Columns {
 		columns = append(columns, column.Name)
@@ -40,3 +310,12 @@ func (d *DBMeta) GetTableColumns(tableName string) (columns []string) {
 func (d *DBMeta) GetTableNameList() []string {
 	return lo.Keys(d.tableMap)
 }
+
+// Tables 所有表, 按表名排序
+func (d *DBMeta) Tables() []Table {
+	tables := lo.Values(d.tableMap)
+	sort.Slice(tables, func(i, j int) bool {
+		return tables[i].Name < tables[j].Name
+	})
+	return tables
+}
@@ -0,0 +1,328 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/glennliao/apijson-go/consts"
+	"github.com/glennliao/apijson-go/util"
+	"github.com/samber/lo"
+)
+
+// Problem 配置检查发现的问题
+type Problem struct {
+	Source  string // 来源, 如 _access User、_request Todo@POST@1 或配置文件
+	Field   string // 字段, 如 FieldsGet.default.Out.password
+	Message string
+}
+
+func (p Problem) String() string {
+	if p.Field == "" {
+		return p.Source + ": " + p.Message
+	}
+	return p.Source + ": " + p.Field + ": " + p.Message
+}
+
+// LintOptions 检查时使用的信息
+type LintOptions struct {
+	// 为 nil 时使用 DbMetaProvider 加载, 如在 CI 中使用导出的表结构
+	DbMeta *DBMeta
+	// 已注册的执行器, 为 nil 时不检查
+	QueryExecutors  []string
+	ActionExecutors []string
+}
+
+// Lint 检查各 provider 中的 _access 与 _request, 返回发现的所有问题; provider 加载失败时返回错误
+// 检查 表与字段是否存在、角色是否存在、UPDATE 中的函数是否绑定、执行器与主键生成是否注册 等
+func (c *Config) Lint(ctx context.Context, opts LintOptions) (problems []Problem, err error) {
+	defer util.RecoverErr(&err, "lint config")
+
+	l := &linter{
+		ctx:    ctx,
+		config: c,
+		opts:   opts,
+		access: map[string]*AccessConfig{},
+	}
+
+	if l.opts.DbMeta == nil {
+		l.opts.DbMeta = c.loadDbMeta(ctx)
+	}
+
+	var accessList []AccessConfig
+	if provider := c.accessListProvider(); provider != nil {
+		accessList = provider(ctx)
+	}
+	var requestList []RequestConfig
+	if provider := c.requestListProvider(); provider != nil {
+		requestList = provider(ctx)
+	}
+
+	access := c.Access.clone()
+	if err := access.loadRoleInherits(); err != nil {
+		l.add("Access", "", "%s", err)
+	}
+	l.roles = access.roles()
+
+	for i := range accessList {
+		l.lintAccess(&accessList[i])
+	}
+	for i := range requestList {
+		l.lintRequest(&requestList[i])
+	}
+
+	return l.problems, nil
+}
+
+// LoadDbMeta 使用 DbMetaProvider 加载表结构, 不修改当前快照, 用于导出表结构
+func (c *Config) LoadDbMeta(ctx context.Context) (dbMeta *DBMeta, err error) {
+	defer util.RecoverErr(&err, "load db meta")
+	return c.loadDbMeta(ctx), nil
+}
+
+func (c *Config) loadDbMeta(ctx context.Context) *DBMeta {
+	if provider := c.dbMetaProvider(); provider != nil {
+		return NewDbMeta(provider(ctx))
+	}
+	return c.DbMeta
+}
+
+type linter struct {
+	ctx    context.Context
+	config *Config
+	opts   LintOptions
+
+	access   map[string]*AccessConfig // alias -> access
+	roles    []string                 // 所有角色, 包括角色继承中的
+	problems []Problem
+}
+
+func (l *linter) add(source string, field string, format string, args ...any) {
+	l.problems = append(l.problems, Problem{Source: source, Field: field, Message: fmt.Sprintf(format, args...)})
+}
+
+func (l *linter) hasRole(role string) bool {
+	return role == consts.DENY || lo.Contains(l.roles, role)
+}
+
+func (l *linter) lintAccess(access *AccessConfig) {
+	if access.Alias == "" {
+		access.Alias = access.Name
+	}
+
+	source := access.Source
+	if source == "" {
+		source = "_access " + access.Alias
+	}
+
+	if access.Name == "" {
+		l.add(source, "Name", "required")
+	}
+
+	if other, exists := l.access[access.Alias]; exists {
+		otherSource := other.Source
+		if otherSource == "" {
+			otherSource = "_access " + other.Alias
+		}
+		l.add(source, "Alias", "duplicate alias %s, also in %s", access.Alias, otherSource)
+	} else {
+		l.access[access.Alias] = access
+	}
+
+	// 角色
+	checkRoles := func(field string, roles []string) {
+		for _, role := range roles {
+			if !l.hasRole(role) {
+				l.add(source, field, "unknown role %s", role)
+			}
+		}
+	}
+	checkRoles("Get", access.Get)
+	checkRoles("Head", access.Head)
+	checkRoles("Gets", access.Gets)
+	checkRoles("Heads", access.Heads)
+	checkRoles("Post", access.Post)
+	checkRoles("Put", access.Put)
+	checkRoles("Delete", access.Delete)
+
+	// 按角色配置的, default 为未配置的角色
+	checkRoleKeys := func(field string, roles []string) {
+		checkRoles(field, lo.Without(roles, "default"))
+	}
+	checkRoleKeys("FieldsGet", sortedKeys(access.FieldsGet))
+	checkRoleKeys("FieldsPost", sortedKeys(access.FieldsPost))
+	checkRoleKeys("FieldsPut", sortedKeys(access.FieldsPut))
+	checkRoleKeys("RowPolicy", sortedKeys(access.RowPolicy))
+	for i, rule := range access.Rules {
+		if rule == nil {
+			continue
+		}
+		for _, role := range rule.Roles {
+			if !l.hasRole(role) {
+				l.add(source, fmt.Sprintf("Rules[%d].Roles", i), "unknown role %s", role)
+			}
+		}
+	}
+
+	// 行级策略与规则
+	if err := access.compile(); err != nil {
+		l.add(source, "", "%s", err)
+	}
+
+	if access.RowKeyGen != "" {
+		if _, exists := l.config.rowKeyGenFuncMap[access.RowKeyGen]; !exists {
+			l.add(source, "RowKeyGen", "rowKeyGen %s not registered", access.RowKeyGen)
+		}
+	}
+
+	if l.opts.QueryExecutors != nil {
+		executor := access.Executor
+		if executor == "" {
+			executor = "default"
+		}
+		if !lo.Contains(l.opts.QueryExecutors, executor) {
+			l.add(source, "Executor", "query executor %s not registered", executor)
+		}
+	}
+
+	l.lintColumns(source, access)
+}
+
+// lintColumns 检查配置中的字段是否为表中的字段, 未加载表结构时不检查
+func (l *linter) lintColumns(source string, access *AccessConfig) {
+	dbMeta := l.opts.DbMeta
+	if dbMeta == nil || len(dbMeta.GetTableNameList()) == 0 || access.Name == "" {
+		return
+	}
+
+	if !lo.Contains(dbMeta.GetTableNameList(), access.Name) {
+		l.add(source, "Name", "table %s not found", access.Name)
+		return
+	}
+
+	columns := dbMeta.GetTableColumns(access.Name)
+	check := func(field string, column string) {
+		if column == "" || column == "*" {
+			return
+		}
+		if !lo.Contains(columns, l.config.DbFieldStyle(l.ctx, access.Name, column)) {
+			l.add(source, field, "column %s not found in %s", column, access.Name)
+		}
+	}
+
+	for _, key := range access.RowKeys() {
+		check("RowKey", key)
+	}
+	check("VersionColumn", access.VersionColumn)
+	check("SoftDeleteColumn", access.SoftDeleteColumn)
+	check("TenantColumn", access.TenantColumn)
+
+	for _, role := range sortedKeys(access.FieldsGet) {
+		fieldsGet := access.FieldsGet[role]
+		if fieldsGet == nil {
+			continue
+		}
+		for _, column := range sortedKeys(fieldsGet.In) {
+			check("FieldsGet."+role+".In", column)
+		}
+		for _, column := range sortedKeys(fieldsGet.Out) {
+			check("FieldsGet."+role+".Out", column)
+		}
+	}
+	for _, role := range sortedKeys(access.FieldsPost) {
+		for _, column := range access.FieldsPost[role] {
+			check("FieldsPost."+role, column)
+		}
+	}
+	for _, role := range sortedKeys(access.FieldsPut) {
+		for _, column := range access.FieldsPut[role] {
+			check("FieldsPut."+role, column)
+		}
+	}
+	for _, column := range sortedKeys(access.AutoFill) {
+		check("AutoFill", column)
+	}
+	for _, role := range sortedKeys(access.policies) {
+		for _, method := range sortedKeys(access.policies[role]) {
+			for _, cond := range access.policies[role][method] {
+				check("RowPolicy."+role+"."+method, cond.Column)
+			}
+		}
+	}
+	for i, rule := range access.rules {
+		for _, cond := range rule.where {
+			check(fmt.Sprintf("Rules[%d].Where", i), cond.Column)
+		}
+	}
+}
+
+func (l *linter) lintRequest(request *RequestConfig) {
+	method := strings.ToUpper(request.Method)
+
+	source := request.Source
+	if source == "" {
+		source = "_request " + getRequestFullKey(request.Tag, request.Method, request.Version)
+	}
+
+	if !lo.Contains([]string{http.MethodPost, http.MethodPut, http.MethodDelete, consts.MethodUpsert}, method) {
+		l.add(source, "Method", "unknown method %s", request.Method)
+	}
+
+	for _, key := range sortedKeys(request.Structure) {
+		name, _ := getTag(key)
+		if _, exists := l.access[name]; !exists {
+			l.add(source, "Structure."+key, "access %s not found", name)
+			continue
+		}
+
+		structure := request.Structure[key]
+		if structure == nil {
+			continue
+		}
+
+		for _, k := range sortedKeys(structure.Update) {
+			if !strings.HasSuffix(k, consts.FunctionsKeySuffix) {
+				continue
+			}
+			funcStr, _ := structure.Update[k].(string)
+			functionName, _ := util.ParseFunctionsStr(funcStr)
+			if _, bound := l.config.Functions.funcMap[functionName]; !bound {
+				l.add(source, "Structure."+key+".UPDATE."+k, "function %s not bound", functionName)
+			}
+		}
+
+		if _, err := structure.MatchAffected(0); err != nil {
+			l.add(source, "Structure."+key+".AFFECTED", "invalid %s", structure.Affected)
+		}
+	}
+
+	execQueue := request.ExecQueue
+	if len(execQueue) == 0 {
+		tag, _ := getTag(request.Tag)
+		execQueue = []string{tag}
+	}
+	for _, key := range execQueue {
+		name, _ := getTag(key)
+		_, exists := request.Structure[key]
+		_, nameExists := request.Structure[name]
+		if !exists && !nameExists {
+			l.add(source, "ExecQueue", "%s not in structure", key)
+		}
+	}
+
+	if l.opts.ActionExecutors != nil {
+		for _, key := range sortedKeys(request.Executor) {
+			if !lo.Contains(l.opts.ActionExecutors, request.Executor[key]) {
+				l.add(source, "Executor."+key, "action executor %s not registered", request.Executor[key])
+			}
+		}
+	}
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := lo.Keys(m)
+	sort.Strings(keys)
+	return keys
+}
@@ -1,25 +1,23 @@
 package config
 
 import (
+	"context"
 	"net/http"
-
-	"github.com/samber/lo"
 )
 
 type QueryConfig struct {
-	access          *Access
-	functions       *functions
-	maxTreeDeep     int
-	maxTreeWidth    int
-	defaultRoleFunc DefaultRole
+	access       *Access
+	functions    *functions
+	maxTreeDeep  int
+	maxTreeWidth int
 }
 
 func (c *QueryConfig) NoVerify() bool {
-	return c.access.NoVerify
+	return c.access.hooks().NoVerify
 }
 
 func (c *QueryConfig) DefaultRoleFunc() DefaultRole {
-	return c.defaultRoleFunc
+	return c.access.hooks().DefaultRoleFunc
 }
 
 func (c *QueryConfig) GetAccessConfig(key string, noVerify bool) (*AccessConfig, error) {
@@ -68,35 +66,30 @@ func (c *ExecutorConfig) TableColumns() []string {
 }
 
 func (c *ExecutorConfig) GetFieldsGetByRole() *FieldsGetValue {
-
-	if val, exists := c.accessConfig.FieldsGet[c.role]; exists {
-		return val
-	}
-
-	return c.accessConfig.FieldsGet["default"]
+	return c.accessConfig.GetFieldsGetByRole(c.role)
 }
 
 func (c *ExecutorConfig) GetFieldsGetOutByRole() []string {
-	var fieldsMap map[string]string
-
-	if val, exists := c.accessConfig.FieldsGet[c.role]; exists {
-		fieldsMap = val.Out
-	} else {
-		fieldsMap = c.accessConfig.FieldsGet["default"].Out
-	}
-	return lo.Keys(fieldsMap)
+	return c.accessConfig.GetFieldsGetOutByRole(c.role)
 }
 
 func (c *ExecutorConfig) GetFieldsGetInByRole() map[string][]string {
-	var inFieldsMap map[string][]string
+	return c.accessConfig.GetFieldsGetInByRole(c.role)
+}
 
-	if val, exists := c.accessConfig.FieldsGet[c.role]; exists {
-		inFieldsMap = val.In
-	} else {
-		inFieldsMap = c.accessConfig.FieldsGet["default"].In
-	}
+// HasAccessRole 角色或其继承的角色是否有当前请求方式的权限
+func (c *ExecutorConfig) HasAccessRole(role string) bool {
+	return c.accessConfig.HasRole(c.AccessRoles(), role)
+}
+
+// RowPolicyWhere 将角色的行级策略加入查询条件
+func (c *ExecutorConfig) RowPolicyWhere(ctx context.Context, role string, condition *ConditionRet) error {
+	return c.accessConfig.RowPolicyWhere(ctx, c.method, role, condition)
+}
 
-	return inFieldsMap
+// EvalRules 求值属性访问规则, 命中的条件加入查询条件
+func (c *ExecutorConfig) EvalRules(ctx context.Context, role string, req map[string]any, condition *ConditionRet) error {
+	return c.accessConfig.EvalRules(ctx, c.method, role, req, condition)
 }
 
 func (c *ExecutorConfig) AccessRoles() []string {
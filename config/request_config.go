@@ -1,6 +1,7 @@
 package config
 
 import (
+	"strconv"
 	"strings"
 
 	"github.com/glennliao/apijson-go/consts"
@@ -23,6 +24,9 @@ type RequestConfig struct {
 	Executor  map[string]string
 	// 是否开启事务
 	Transaction *bool
+
+	// 配置来源, 如配置文件路径与行号, 用于 Lint 时定位; 为空时使用 _request 与 tag@method@version
+	Source string `json:"-"`
 }
 
 type Structure struct {
@@ -39,6 +43,43 @@ type Structure struct {
 	Replace g.Map `json:"REPLACE,omitempty"`
 	// 存在时移除
 	Remove []string `json:"REMOVE,omitempty"`
+
+	// 每一项期望的影响行数, 如 1(=1)、>=1, 不满足时回滚并返回错误
+	Affected string `json:"AFFECTED,omitempty"`
+}
+
+// MatchAffected 判断单项的影响行数是否符合 AFFECTED
+func (s *Structure) MatchAffected(count int64) (bool, error) {
+	if s.Affected == "" {
+		return true, nil
+	}
+
+	expr := strings.TrimSpace(s.Affected)
+	op := "="
+	for _, item := range []string{">=", "<=", ">", "<", "="} {
+		if strings.HasPrefix(expr, item) {
+			op = item
+			expr = strings.TrimSpace(expr[len(item):])
+			break
+		}
+	}
+
+	expect, err := strconv.ParseInt(expr, 10, 64)
+	if err != nil {
+		return false, consts.NewValidStructureErr("AFFECTED格式错误: " + s.Affected)
+	}
+
+	switch op {
+	case ">=":
+		return count >= expect, nil
+	case "<=":
+		return count <= expect, nil
+	case ">":
+		return count > expect, nil
+	case "<":
+		return count < expect, nil
+	}
+	return count == expect, nil
 }
 
 type RequestConfigs struct {
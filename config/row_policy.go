@@ -0,0 +1,215 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/glennliao/apijson-go/consts"
+	"github.com/gogf/gf/v2/util/gconv"
+	"github.com/samber/lo"
+)
+
+// 行级策略的条件格式: 字段 操作符 [值], 值为 $ctx.key、$now、数字、'字符串'、true/false
+var rowPolicyPattern = regexp.MustCompile(`(?i)^\s*([A-Za-z_][A-Za-z0-9_]*)\s*(=|!=|>=|<=|>|<|NOT\s+IN|IN|IS\s+NOT\s+NULL|IS\s+NULL)\s*(.*?)\s*$`)
+
+// rowPolicyDenyAll 上下文的值为空时使用的条件, 不匹配任何行
+const rowPolicyDenyAll = "1=0"
+
+type rowPolicyCond struct {
+	Column string
+	Op     string
+	Value  any // 常量, 或 $ 开头的待解析的值
+}
+
+// compileRowPolicy 解析 _access 中的 RowPolicy, 角色 -> 请求方式 -> 条件
+func compileRowPolicy(rowPolicy map[string]map[string][]string) (map[string]map[string][]*rowPolicyCond, error) {
+	if len(rowPolicy) == 0 {
+		return nil, nil
+	}
+
+	policies := map[string]map[string][]*rowPolicyCond{}
+	for role, methods := range rowPolicy {
+		policies[role] = map[string][]*rowPolicyCond{}
+		for method, conds := range methods {
+			method = strings.ToUpper(method)
+			keys := map[string]bool{}
+			for _, cond := range conds {
+				c, err := parseRowPolicyCond(cond)
+				if err != nil {
+					return nil, fmt.Errorf("%s.%s: %w", role, method, err)
+				}
+				key := c.Column + " " + c.Op
+				if keys[key] {
+					return nil, fmt.Errorf("%s.%s: duplicate condition: %s", role, method, cond)
+				}
+				keys[key] = true
+				policies[role][method] = append(policies[role][method], c)
+			}
+		}
+	}
+	return policies, nil
+}
+
+func parseRowPolicyCond(cond string) (*rowPolicyCond, error) {
+	match := rowPolicyPattern.FindStringSubmatch(cond)
+	if match == nil {
+		return nil, fmt.Errorf("invalid condition: %s", cond)
+	}
+
+	c := &rowPolicyCond{
+		Column: match[1],
+		Op:     strings.Join(strings.Fields(strings.ToUpper(match[2])), " "),
+	}
+
+	if c.Op == "IS NULL" || c.Op == "IS NOT NULL" {
+		if match[3] != "" {
+			return nil, fmt.Errorf("invalid condition: %s", cond)
+		}
+		return c, nil
+	}
+
+	val := match[3]
+	switch {
+	case val == "":
+		return nil, fmt.Errorf("condition need value: %s", cond)
+	case strings.HasPrefix(val, "$"):
+		if val != consts.ValueNow && !strings.HasPrefix(val, consts.CtxValuePrefix) {
+			return nil, fmt.Errorf("unknown value %s: %s", val, cond)
+		}
+		c.Value = val
+	case len(val) >= 2 && val[0] == '\'' && val[len(val)-1] == '\'':
+		c.Value = val[1 : len(val)-1]
+	case strings.EqualFold(val, "true"), strings.EqualFold(val, "false"):
+		c.Value = strings.EqualFold(val, "true")
+	default:
+		num, err := strconv.ParseFloat(val, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value %s: %s", val, cond)
+		}
+		c.Value = num
+	}
+
+	if (c.Op == "IN" || c.Op == "NOT IN") && !strings.HasPrefix(gconv.String(c.Value), "$") {
+		return nil, fmt.Errorf("%s need $ctx value: %s", c.Op, cond)
+	}
+
+	return c, nil
+}
+
+// rowPolicy 角色在该请求方式下的策略, 包含 * 中配置的
+// 角色自身未配置策略时使用就近的继承的角色的策略, 通过继承获得访问权限的角色同样受限制
+func (a *AccessConfig) rowPolicy(method string, role string) []*rowPolicyCond {
+	var methods map[string][]*rowPolicyCond
+	for _, r := range a.Roles(role) {
+		if m, exists := a.policies[r]; exists {
+			methods = m
+			break
+		}
+	}
+	return append(append([]*rowPolicyCond{}, methods["*"]...), methods[method]...)
+}
+
+func (a *AccessConfig) resolvePolicyValue(ctx context.Context, val any) (any, error) {
+	if a.access == nil {
+		return val, nil
+	}
+	return a.access.ResolveValue(ctx, val)
+}
+
+// RowPolicyWhere 将角色的行级策略加入 get/put/delete 的条件
+func (a *AccessConfig) RowPolicyWhere(ctx context.Context, method string, role string, condition *ConditionRet) error {
+	conds := a.rowPolicy(method, role)
+	if len(conds) == 0 {
+		return nil
+	}
+
+	if method == consts.MethodUpsert {
+		// upsert 冲突时会修改已存在的行, 无法按策略限制
+		return consts.NewNoAccessErr(a.Alias+"."+consts.MethodUpsert, role)
+	}
+
+	return a.addPolicyWhere(ctx, conds, condition)
+}
+
+func (a *AccessConfig) addPolicyWhere(ctx context.Context, conds []*rowPolicyCond, condition *ConditionRet) error {
+	for _, c := range conds {
+		val, err := a.resolvePolicyValue(ctx, c.Value)
+		if err != nil {
+			return err
+		}
+
+		switch c.Op {
+		case "IS NULL":
+			condition.AddRaw(c.Column, nil)
+		case "IS NOT NULL":
+			condition.AddRaw(c.Column+" IS NOT NULL", nil)
+		case "IN":
+			list := gconv.Interfaces(val)
+			if val == nil || len(list) == 0 {
+				condition.AddRaw(rowPolicyDenyAll, nil)
+				continue
+			}
+			condition.AddRaw(c.Column, list)
+		case "NOT IN":
+			list := gconv.Interfaces(val)
+			if val == nil || len(list) == 0 {
+				continue
+			}
+			condition.AddRaw(c.Column+" NOT IN(?)", list)
+		case "=":
+			if val == nil {
+				condition.AddRaw(rowPolicyDenyAll, nil)
+				continue
+			}
+			condition.AddRaw(c.Column, val)
+		default:
+			if val == nil {
+				condition.AddRaw(rowPolicyDenyAll, nil)
+				continue
+			}
+			condition.AddRaw(c.Column+" "+c.Op, val)
+		}
+	}
+
+	return nil
+}
+
+// RowPolicyData 行级策略对 post 数据的限制: = 的字段未传入时填充, 传入时需一致; IN 的字段需在列表中
+// 其他操作符在 post 时不生效
+func (a *AccessConfig) RowPolicyData(ctx context.Context, role string, data map[string]any) error {
+	for _, c := range a.rowPolicy(http.MethodPost, role) {
+		if c.Op != "=" && c.Op != "IN" {
+			continue
+		}
+
+		val, err := a.resolvePolicyValue(ctx, c.Value)
+		if err != nil {
+			return err
+		}
+		if val == nil {
+			return consts.NewNoAccessErr(a.Alias+"."+c.Column, role)
+		}
+
+		cur, exists := data[c.Column]
+
+		if c.Op == "=" {
+			if !exists {
+				data[c.Column] = val
+				continue
+			}
+			if gconv.String(cur) != gconv.String(val) {
+				return consts.NewNoAccessErr(a.Alias+"."+c.Column, role)
+			}
+			continue
+		}
+
+		if !exists || !lo.Contains(gconv.Strings(val), gconv.String(cur)) {
+			return consts.NewNoAccessErr(a.Alias+"."+c.Column, role)
+		}
+	}
+	return nil
+}
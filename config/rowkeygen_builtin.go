@@ -0,0 +1,377 @@
+package config
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+)
+
+// 内置的主键生成策略, 在 AccessConfig.RowKeyGen 中使用
+const (
+	RowKeyGenSnowflake = "snowflake" // int64, 节点号为 Config.SnowflakeNode, 需按实例区分
+	RowKeyGenUUID      = "uuid"      // UUIDv4
+	RowKeyGenUUIDv7    = "uuidv7"    // 按时间递增的 UUIDv7
+	RowKeyGenULID      = "ulid"
+	RowKeyGenKSUID     = "ksuid"
+	RowKeyGenShortId   = "shortid" // 前缀为 Config.ShortIdPrefix 中按 access 设置的, 长度为 Config.ShortIdLength
+)
+
+func regBuiltinRowKeyGen(c *Config) {
+	c.RowKeyGenFunc(RowKeyGenSnowflake, c.snowflakeRowKeyGen())
+	c.RowKeyGenFunc(RowKeyGenUUID, stringRowKeyGen(NewUUIDv4))
+	c.RowKeyGenFunc(RowKeyGenUUIDv7, stringRowKeyGen(NewUUIDv7))
+	c.RowKeyGenFunc(RowKeyGenULID, stringRowKeyGen(NewULID))
+	c.RowKeyGenFunc(RowKeyGenKSUID, stringRowKeyGen(NewKSUID))
+	c.RowKeyGenFunc(RowKeyGenShortId, func(ctx context.Context, req *RowKeyGenReq, ret *RowKeyGenRet) error {
+		id, err := NewShortId(c.ShortIdPrefix[req.AccessName], c.ShortIdLength)
+		if err != nil {
+			return err
+		}
+		ret.RowKey(id)
+		return nil
+	})
+}
+
+// snowflakeRowKeyGen 首次使用时按 Config.SnowflakeNode 创建, 之后修改节点号不生效
+func (c *Config) snowflakeRowKeyGen() RowKeyGenFuncHandler {
+	var (
+		mu sync.Mutex
+		s  *Snowflake
+	)
+	return func(ctx context.Context, req *RowKeyGenReq, ret *RowKeyGenRet) error {
+		mu.Lock()
+		if s == nil {
+			if c.SnowflakeNode < 0 {
+				mu.Unlock()
+				return errors.New("snowflake node not set: Config.SnowflakeNode")
+			}
+			var err error
+			s, err = NewSnowflake(c.SnowflakeNode)
+			if err != nil {
+				mu.Unlock()
+				return err
+			}
+		}
+		mu.Unlock()
+
+		id, err := s.Next()
+		if err != nil {
+			return err
+		}
+		ret.RowKey(id)
+		return nil
+	}
+}
+
+func stringRowKeyGen(gen func() (string, error)) RowKeyGenFuncHandler {
+	return func(ctx context.Context, req *RowKeyGenReq, ret *RowKeyGenRet) error {
+		id, err := gen()
+		if err != nil {
+			return err
+		}
+		ret.RowKey(id)
+		return nil
+	}
+}
+
+// SnowflakeRowKeyGen 指定节点号(0-1023)的雪花id生成, 同时运行的实例需使用不同的节点号; 用于注册内置之外的 snowflake
+func SnowflakeRowKeyGen(node int64) (RowKeyGenFuncHandler, error) {
+	s, err := NewSnowflake(node)
+	if err != nil {
+		return nil, err
+	}
+	return func(ctx context.Context, req *RowKeyGenReq, ret *RowKeyGenRet) error {
+		id, err := s.Next()
+		if err != nil {
+			return err
+		}
+		ret.RowKey(id)
+		return nil
+	}, nil
+}
+
+// ShortIdRowKeyGen 带前缀的随机短id, 如 ShortIdRowKeyGen("ord_", 16) 生成 ord_3hK9xQ...
+func ShortIdRowKeyGen(prefix string, length int) (RowKeyGenFuncHandler, error) {
+	if err := checkShortIdLength(length); err != nil {
+		return nil, err
+	}
+	return stringRowKeyGen(func() (string, error) {
+		return NewShortId(prefix, length)
+	}), nil
+}
+
+// ---- snowflake ----
+
+const (
+	snowflakeEpoch    = int64(1577836800000) // 2020-01-01 00:00:00 UTC
+	snowflakeNodeBits = 10
+	snowflakeSeqBits  = 12
+	snowflakeMaxNode  = -1 ^ (-1 << snowflakeNodeBits)
+	snowflakeSeqMask  = -1 ^ (-1 << snowflakeSeqBits)
+
+	// 时钟回拨不超过该时间时等待, 超过时返回错误
+	snowflakeMaxBackwards = 5 * time.Millisecond
+)
+
+// Snowflake 雪花id: 41位毫秒时间 + 10位节点号 + 12位序号
+type Snowflake struct {
+	mu     sync.Mutex
+	node   int64
+	lastMs int64
+	seq    int64
+}
+
+func NewSnowflake(node int64) (*Snowflake, error) {
+	if node < 0 || node > snowflakeMaxNode {
+		return nil, errors.New("snowflake node must be between 0 and 1023")
+	}
+	return &Snowflake{node: node}, nil
+}
+
+// Next 下一个id; 同一毫秒内序号用完或时钟小幅回拨时, 在锁外等待后重试
+func (s *Snowflake) Next() (int64, error) {
+	for {
+		id, wait, err := s.next()
+		if err != nil || wait == 0 {
+			return id, err
+		}
+		time.Sleep(wait)
+	}
+}
+
+// next 生成id, 需要等待时返回等待的时间
+func (s *Snowflake) next() (int64, time.Duration, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now().UnixMilli() - snowflakeEpoch
+	switch {
+	case now < s.lastMs:
+		backwards := time.Duration(s.lastMs-now) * time.Millisecond
+		if backwards > snowflakeMaxBackwards {
+			return 0, 0, fmt.Errorf("snowflake: clock moved backwards by %s", backwards)
+		}
+		return 0, backwards, nil
+	case now == s.lastMs:
+		if s.seq == snowflakeSeqMask {
+			return 0, 100 * time.Microsecond, nil
+		}
+		s.seq++
+	default:
+		s.seq = 0
+	}
+	s.lastMs = now
+
+	return now<<(snowflakeNodeBits+snowflakeSeqBits) | s.node<<snowflakeSeqBits | s.seq, 0, nil
+}
+
+// ---- uuid ----
+
+func NewUUIDv4() (string, error) {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", err
+	}
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return formatUUID(b), nil
+}
+
+func NewUUIDv7() (string, error) {
+	return uuidV7.next()
+}
+
+// uuidV7Gen 同一毫秒内使用 rand_a 的12位作为递增序号, 保证单进程内有序
+type uuidV7Gen struct {
+	mu     sync.Mutex
+	lastMs int64
+	seq    uint16
+}
+
+var uuidV7 = &uuidV7Gen{}
+
+func (g *uuidV7Gen) next() (string, error) {
+	var b [16]byte
+	if _, err := rand.Read(b[8:]); err != nil {
+		return "", err
+	}
+
+	g.mu.Lock()
+	ms := time.Now().UnixMilli()
+	if ms <= g.lastMs {
+		ms = g.lastMs
+		g.seq++
+		if g.seq > 0xfff {
+			ms++
+			g.seq = 0
+		}
+	} else {
+		g.seq = 0
+	}
+	g.lastMs = ms
+	seq := g.seq
+	g.mu.Unlock()
+
+	b[0] = byte(ms >> 40)
+	b[1] = byte(ms >> 32)
+	b[2] = byte(ms >> 24)
+	b[3] = byte(ms >> 16)
+	b[4] = byte(ms >> 8)
+	b[5] = byte(ms)
+	b[6] = 0x70 | byte(seq>>8)
+	b[7] = byte(seq)
+	b[8] = b[8]&0x3f | 0x80
+	return formatUUID(b), nil
+}
+
+func formatUUID(b [16]byte) string {
+	var buf [36]byte
+	hex.Encode(buf[0:8], b[0:4])
+	buf[8] = '-'
+	hex.Encode(buf[9:13], b[4:6])
+	buf[13] = '-'
+	hex.Encode(buf[14:18], b[6:8])
+	buf[18] = '-'
+	hex.Encode(buf[19:23], b[8:10])
+	buf[23] = '-'
+	hex.Encode(buf[24:], b[10:])
+	return string(buf[:])
+}
+
+// ---- ulid ----
+
+const crockford = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// ulidGen 同一毫秒内随机部分递增, 保证单进程内有序
+type ulidGen struct {
+	mu     sync.Mutex
+	lastMs int64
+	last   [10]byte
+}
+
+var ulid = &ulidGen{}
+
+func NewULID() (string, error) {
+	return ulid.next()
+}
+
+func (g *ulidGen) next() (string, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	ms := time.Now().UnixMilli()
+	if ms <= g.lastMs && increment(g.last[:]) {
+		ms = g.lastMs
+	} else {
+		if ms <= g.lastMs {
+			ms = g.lastMs + 1 // 随机部分溢出
+		}
+		if _, err := rand.Read(g.last[:]); err != nil {
+			return "", err
+		}
+	}
+	g.lastMs = ms
+
+	var b [16]byte
+	b[0] = byte(ms >> 40)
+	b[1] = byte(ms >> 32)
+	b[2] = byte(ms >> 24)
+	b[3] = byte(ms >> 16)
+	b[4] = byte(ms >> 8)
+	b[5] = byte(ms)
+	copy(b[6:], g.last[:])
+
+	// 128位按5位一组编码为26个字符, 最高位补0
+	hi := binary.BigEndian.Uint64(b[:8])
+	lo := binary.BigEndian.Uint64(b[8:])
+	var buf [26]byte
+	for i := 25; i >= 0; i-- {
+		buf[i] = crockford[lo&0x1f]
+		lo = lo>>5 | hi<<59
+		hi >>= 5
+	}
+	return string(buf[:]), nil
+}
+
+// increment 大端字节加一, 溢出时返回false
+func increment(b []byte) bool {
+	for i := len(b) - 1; i >= 0; i-- {
+		b[i]++
+		if b[i] != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// ---- ksuid ----
+
+const (
+	ksuidEpoch = int64(1400000000)
+	base62     = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+)
+
+// NewKSUID 4字节秒级时间 + 16字节随机数, base62编码为27个字符
+func NewKSUID() (string, error) {
+	var b [20]byte
+	binary.BigEndian.PutUint32(b[:4], uint32(time.Now().Unix()-ksuidEpoch))
+	if _, err := rand.Read(b[4:]); err != nil {
+		return "", err
+	}
+
+	var buf [27]byte
+	n := new(big.Int).SetBytes(b[:])
+	mod := new(big.Int)
+	radix := big.NewInt(62)
+	for i := len(buf) - 1; i >= 0; i-- {
+		n.DivMod(n, radix, mod)
+		buf[i] = base62[mod.Int64()]
+	}
+	return string(buf[:]), nil
+}
+
+// ---- short id ----
+
+// shortIdMinLength 短id随机部分的最小长度, 62^8 约 2e14
+const shortIdMinLength = 8
+
+func checkShortIdLength(length int) error {
+	if length < shortIdMinLength {
+		return fmt.Errorf("short id length must be at least %d", shortIdMinLength)
+	}
+	return nil
+}
+
+// NewShortId 前缀 + length 位随机的 base62 字符, length 不小于 8
+func NewShortId(prefix string, length int) (string, error) {
+	if err := checkShortIdLength(length); err != nil {
+		return "", err
+	}
+
+	buf := make([]byte, 0, len(prefix)+length)
+	buf = append(buf, prefix...)
+
+	var rb [64]byte
+	for len(buf) < len(prefix)+length {
+		if _, err := rand.Read(rb[:]); err != nil {
+			return "", err
+		}
+		for _, c := range rb {
+			// 丢弃 248 以上的值, 避免取模偏差
+			if c >= 248 {
+				continue
+			}
+			buf = append(buf, base62[c%62])
+			if len(buf) == len(prefix)+length {
+				break
+			}
+		}
+	}
+	return string(buf), nil
+}
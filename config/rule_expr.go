@@ -0,0 +1,681 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/gogf/gf/v2/util/gconv"
+)
+
+// 规则表达式: 仅支持字面量、变量、比较/逻辑运算与白名单函数, 没有循环与赋值, 在本地求值
+//
+//	字面量: 1、1.5、'str'、"str"、true、false、null、[1, 2]
+//	变量:   principal.key (Access.CtxValueFunc 获取)、req.field (节点请求)、method、role、access
+//	运算:   == != < <= > >= in && || ! + - (), in 的右侧需为列表
+//	函数:   hour() minute() weekday() date() time() len(x) contains(list, x) lower(s) upper(s)
+
+const (
+	ruleExprMaxLen   = 1024
+	ruleExprMaxDepth = 32
+)
+
+type ruleEnv struct {
+	ctx       context.Context
+	principal func(ctx context.Context, key string) (any, error)
+	vars      map[string]any
+	now       time.Time
+}
+
+type ruleExpr interface {
+	eval(env *ruleEnv) (any, error)
+}
+
+// ---- lexer ----
+
+type ruleToken struct {
+	kind string // num str ident op eof
+	val  string
+	pos  int
+}
+
+func lexRuleExpr(src string) ([]ruleToken, error) {
+	var tokens []ruleToken
+	for i := 0; i < len(src); {
+		c := rune(src[i])
+		switch {
+		case unicode.IsSpace(c):
+			i++
+		case unicode.IsDigit(c):
+			start := i
+			for i < len(src) && (unicode.IsDigit(rune(src[i])) || src[i] == '.') {
+				i++
+			}
+			tokens = append(tokens, ruleToken{"num", src[start:i], start})
+		case c == '\'' || c == '"':
+			start := i
+			i++
+			var sb strings.Builder
+			for i < len(src) && rune(src[i]) != c {
+				if src[i] == '\\' && i+1 < len(src) {
+					i++
+				}
+				sb.WriteByte(src[i])
+				i++
+			}
+			if i >= len(src) {
+				return nil, fmt.Errorf("unterminated string at %d", start)
+			}
+			i++
+			tokens = append(tokens, ruleToken{"str", sb.String(), start})
+		case c == '_' || unicode.IsLetter(c):
+			start := i
+			for i < len(src) && (src[i] == '_' || unicode.IsLetter(rune(src[i])) || unicode.IsDigit(rune(src[i]))) {
+				i++
+			}
+			tokens = append(tokens, ruleToken{"ident", src[start:i], start})
+		default:
+			if i+1 < len(src) {
+				two := src[i : i+2]
+				switch two {
+				case "==", "!=", "<=", ">=", "&&", "||":
+					tokens = append(tokens, ruleToken{"op", two, i})
+					i += 2
+					continue
+				}
+			}
+			if strings.ContainsRune("<>!+-()[],.", c) {
+				tokens = append(tokens, ruleToken{"op", string(c), i})
+				i++
+				continue
+			}
+			return nil, fmt.Errorf("unexpected %q at %d", c, i)
+		}
+	}
+	return append(tokens, ruleToken{"eof", "", len(src)}), nil
+}
+
+// ---- parser ----
+
+type ruleParser struct {
+	tokens []ruleToken
+	pos    int
+	depth  int
+}
+
+func compileRuleExpr(src string) (ruleExpr, error) {
+	if len(src) > ruleExprMaxLen {
+		return nil, fmt.Errorf("expression too long")
+	}
+	tokens, err := lexRuleExpr(src)
+	if err != nil {
+		return nil, err
+	}
+	p := &ruleParser{tokens: tokens}
+	e, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.kind != "eof" {
+		return nil, fmt.Errorf("unexpected %q at %d", tok.val, tok.pos)
+	}
+	return e, nil
+}
+
+func (p *ruleParser) peek() ruleToken { return p.tokens[p.pos] }
+
+func (p *ruleParser) next() ruleToken {
+	tok := p.tokens[p.pos]
+	if tok.kind != "eof" {
+		p.pos++
+	}
+	return tok
+}
+
+func (p *ruleParser) isOp(val string) bool {
+	tok := p.peek()
+	return tok.kind == "op" && tok.val == val
+}
+
+func (p *ruleParser) expect(val string) error {
+	tok := p.next()
+	if tok.kind != "op" || tok.val != val {
+		return fmt.Errorf("expect %q at %d", val, tok.pos)
+	}
+	return nil
+}
+
+func (p *ruleParser) enter() error {
+	p.depth++
+	if p.depth > ruleExprMaxDepth {
+		return fmt.Errorf("expression too deep")
+	}
+	return nil
+}
+
+func (p *ruleParser) parseOr() (ruleExpr, error) {
+	if err := p.enter(); err != nil {
+		return nil, err
+	}
+	defer func() { p.depth-- }()
+
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.isOp("||") {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &ruleLogic{op: "||", left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *ruleParser) parseAnd() (ruleExpr, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	for p.isOp("&&") {
+		p.next()
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		left = &ruleLogic{op: "&&", left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *ruleParser) parseNot() (ruleExpr, error) {
+	if p.isOp("!") {
+		p.next()
+		if err := p.enter(); err != nil {
+			return nil, err
+		}
+		defer func() { p.depth-- }()
+		e, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return &ruleNot{e: e}, nil
+	}
+	return p.parseCompare()
+}
+
+var ruleCompareOps = map[string]bool{"==": true, "!=": true, "<": true, "<=": true, ">": true, ">=": true}
+
+func (p *ruleParser) parseCompare() (ruleExpr, error) {
+	left, err := p.parseAdd()
+	if err != nil {
+		return nil, err
+	}
+
+	tok := p.peek()
+	op := ""
+	switch {
+	case tok.kind == "op" && ruleCompareOps[tok.val]:
+		op = tok.val
+	case tok.kind == "ident" && tok.val == "in":
+		op = "in"
+	}
+	if op == "" {
+		return left, nil
+	}
+	p.next()
+
+	right, err := p.parseAdd()
+	if err != nil {
+		return nil, err
+	}
+	if op == "in" && ruleIsString(right) {
+		return nil, fmt.Errorf("in need list at %d", tok.pos)
+	}
+	return &ruleCompare{op: op, left: left, right: right}, nil
+}
+
+// ruleStringFuncs 返回字符串的函数
+var ruleStringFuncs = map[string]bool{"date": true, "time": true, "lower": true, "upper": true}
+
+// ruleIsString 编译时可确定为字符串的表达式, 不能用于 in 与 contains
+func ruleIsString(e ruleExpr) bool {
+	switch e := e.(type) {
+	case *ruleLiteral:
+		_, ok := e.val.(string)
+		return ok
+	case *ruleCall:
+		return ruleStringFuncs[e.name]
+	}
+	return false
+}
+
+func (p *ruleParser) parseAdd() (ruleExpr, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.isOp("+") || p.isOp("-") {
+		op := p.next().val
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = &ruleArith{op: op, left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *ruleParser) parseUnary() (ruleExpr, error) {
+	if p.isOp("-") {
+		p.next()
+		e, err := p.parsePrimary()
+		if err != nil {
+			return nil, err
+		}
+		return &ruleArith{op: "-", left: &ruleLiteral{val: float64(0)}, right: e}, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *ruleParser) parsePrimary() (ruleExpr, error) {
+	tok := p.next()
+	switch tok.kind {
+	case "num":
+		num, err := strconv.ParseFloat(tok.val, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %s at %d", tok.val, tok.pos)
+		}
+		return &ruleLiteral{val: num}, nil
+
+	case "str":
+		return &ruleLiteral{val: tok.val}, nil
+
+	case "ident":
+		switch tok.val {
+		case "true":
+			return &ruleLiteral{val: true}, nil
+		case "false":
+			return &ruleLiteral{val: false}, nil
+		case "null":
+			return &ruleLiteral{val: nil}, nil
+		}
+
+		if p.isOp("(") {
+			return p.parseCall(tok)
+		}
+
+		v := &ruleVar{name: tok.val}
+		for p.isOp(".") {
+			p.next()
+			field := p.next()
+			if field.kind != "ident" {
+				return nil, fmt.Errorf("expect field at %d", field.pos)
+			}
+			v.path = append(v.path, field.val)
+		}
+		switch v.name {
+		case "principal", "req":
+			if len(v.path) == 0 {
+				return nil, fmt.Errorf("%s need field at %d", v.name, tok.pos)
+			}
+		case "method", "role", "access":
+		default:
+			return nil, fmt.Errorf("unknown variable %s at %d", v.name, tok.pos)
+		}
+		return v, nil
+
+	case "op":
+		switch tok.val {
+		case "(":
+			if err := p.enter(); err != nil {
+				return nil, err
+			}
+			defer func() { p.depth-- }()
+			e, err := p.parseOr()
+			if err != nil {
+				return nil, err
+			}
+			return e, p.expect(")")
+		case "[":
+			list := &ruleList{}
+			for !p.isOp("]") {
+				e, err := p.parseAdd()
+				if err != nil {
+					return nil, err
+				}
+				list.items = append(list.items, e)
+				if !p.isOp(",") {
+					break
+				}
+				p.next()
+			}
+			return list, p.expect("]")
+		}
+	}
+
+	if tok.kind == "eof" {
+		return nil, fmt.Errorf("unexpected end")
+	}
+	return nil, fmt.Errorf("unexpected %q at %d", tok.val, tok.pos)
+}
+
+func (p *ruleParser) parseCall(name ruleToken) (ruleExpr, error) {
+	f, exists := ruleFuncs[name.val]
+	if !exists {
+		return nil, fmt.Errorf("unknown function %s at %d", name.val, name.pos)
+	}
+	p.next() // (
+
+	call := &ruleCall{name: name.val, f: f.f}
+	for !p.isOp(")") {
+		e, err := p.parseAdd()
+		if err != nil {
+			return nil, err
+		}
+		call.args = append(call.args, e)
+		if !p.isOp(",") {
+			break
+		}
+		p.next()
+	}
+	if err := p.expect(")"); err != nil {
+		return nil, err
+	}
+	if len(call.args) != f.args {
+		return nil, fmt.Errorf("function %s need %d args", name.val, f.args)
+	}
+	if call.name == "contains" && ruleIsString(call.args[0]) {
+		return nil, fmt.Errorf("function contains need list at %d", name.pos)
+	}
+	return call, nil
+}
+
+// ---- ast ----
+
+type ruleLiteral struct{ val any }
+
+func (e *ruleLiteral) eval(env *ruleEnv) (any, error) { return e.val, nil }
+
+type ruleList struct{ items []ruleExpr }
+
+func (e *ruleList) eval(env *ruleEnv) (any, error) {
+	list := make([]any, len(e.items))
+	for i, item := range e.items {
+		v, err := item.eval(env)
+		if err != nil {
+			return nil, err
+		}
+		list[i] = v
+	}
+	return list, nil
+}
+
+type ruleVar struct {
+	name string
+	path []string
+}
+
+func (e *ruleVar) eval(env *ruleEnv) (any, error) {
+	var (
+		v    any
+		path = e.path
+	)
+
+	if e.name == "principal" {
+		var err error
+		v, err = env.principal(env.ctx, path[0])
+		if err != nil {
+			return nil, err
+		}
+		path = path[1:]
+	} else {
+		v = env.vars[e.name]
+	}
+
+	for _, field := range path {
+		m, ok := v.(map[string]any)
+		if !ok {
+			m = gconv.Map(v)
+		}
+		if m == nil {
+			return nil, nil
+		}
+		v = m[field]
+	}
+	return v, nil
+}
+
+type ruleNot struct{ e ruleExpr }
+
+func (e *ruleNot) eval(env *ruleEnv) (any, error) {
+	v, err := e.e.eval(env)
+	if err != nil {
+		return nil, err
+	}
+	b, err := ruleBool(v)
+	return !b, err
+}
+
+type ruleLogic struct {
+	op          string
+	left, right ruleExpr
+}
+
+func (e *ruleLogic) eval(env *ruleEnv) (any, error) {
+	lv, err := e.left.eval(env)
+	if err != nil {
+		return nil, err
+	}
+	l, err := ruleBool(lv)
+	if err != nil {
+		return nil, err
+	}
+	if (e.op == "&&" && !l) || (e.op == "||" && l) {
+		return l, nil
+	}
+	rv, err := e.right.eval(env)
+	if err != nil {
+		return nil, err
+	}
+	return ruleBool(rv)
+}
+
+type ruleCompare struct {
+	op          string
+	left, right ruleExpr
+}
+
+func (e *ruleCompare) eval(env *ruleEnv) (any, error) {
+	l, err := e.left.eval(env)
+	if err != nil {
+		return nil, err
+	}
+	r, err := e.right.eval(env)
+	if err != nil {
+		return nil, err
+	}
+
+	switch e.op {
+	case "==":
+		return ruleEqual(l, r), nil
+	case "!=":
+		return !ruleEqual(l, r), nil
+	case "in":
+		return ruleIn(l, r), nil
+	}
+
+	if l == nil || r == nil {
+		return false, nil
+	}
+
+	var cmp int
+	if ln, lok := ruleNumber(l); lok {
+		rn, rok := ruleNumber(r)
+		if !rok {
+			return nil, fmt.Errorf("can not compare %v %s %v", l, e.op, r)
+		}
+		switch {
+		case ln < rn:
+			cmp = -1
+		case ln > rn:
+			cmp = 1
+		}
+	} else {
+		ls, lok := l.(string)
+		rs, rok := r.(string)
+		if !lok || !rok {
+			return nil, fmt.Errorf("can not compare %v %s %v", l, e.op, r)
+		}
+		cmp = strings.Compare(ls, rs)
+	}
+
+	switch e.op {
+	case "<":
+		return cmp < 0, nil
+	case "<=":
+		return cmp <= 0, nil
+	case ">":
+		return cmp > 0, nil
+	default:
+		return cmp >= 0, nil
+	}
+}
+
+type ruleArith struct {
+	op          string
+	left, right ruleExpr
+}
+
+func (e *ruleArith) eval(env *ruleEnv) (any, error) {
+	l, err := e.left.eval(env)
+	if err != nil {
+		return nil, err
+	}
+	r, err := e.right.eval(env)
+	if err != nil {
+		return nil, err
+	}
+
+	ln, lok := ruleNumber(l)
+	rn, rok := ruleNumber(r)
+	if lok && rok {
+		if e.op == "+" {
+			return ln + rn, nil
+		}
+		return ln - rn, nil
+	}
+
+	ls, lok := l.(string)
+	rs, rok := r.(string)
+	if e.op == "+" && lok && rok {
+		return ls + rs, nil
+	}
+	return nil, fmt.Errorf("can not calc %v %s %v", l, e.op, r)
+}
+
+type ruleCall struct {
+	name string
+	f    func(env *ruleEnv, args []any) (any, error)
+	args []ruleExpr
+}
+
+func (e *ruleCall) eval(env *ruleEnv) (any, error) {
+	args := make([]any, len(e.args))
+	for i, arg := range e.args {
+		v, err := arg.eval(env)
+		if err != nil {
+			return nil, err
+		}
+		args[i] = v
+	}
+	return e.f(env, args)
+}
+
+// ---- functions ----
+
+var ruleFuncs = map[string]struct {
+	args int
+	f    func(env *ruleEnv, args []any) (any, error)
+}{
+	"hour":    {0, func(env *ruleEnv, args []any) (any, error) { return float64(env.now.Hour()), nil }},
+	"minute":  {0, func(env *ruleEnv, args []any) (any, error) { return float64(env.now.Minute()), nil }},
+	"weekday": {0, func(env *ruleEnv, args []any) (any, error) { return float64(env.now.Weekday()), nil }},
+	"date":    {0, func(env *ruleEnv, args []any) (any, error) { return env.now.Format("2006-01-02"), nil }},
+	"time":    {0, func(env *ruleEnv, args []any) (any, error) { return env.now.Format("15:04"), nil }},
+	"len": {1, func(env *ruleEnv, args []any) (any, error) {
+		if s, ok := args[0].(string); ok {
+			return float64(len([]rune(s))), nil
+		}
+		rv := reflect.ValueOf(args[0])
+		switch rv.Kind() {
+		case reflect.Slice, reflect.Array, reflect.Map:
+			return float64(rv.Len()), nil
+		}
+		return float64(0), nil
+	}},
+	"contains": {2, func(env *ruleEnv, args []any) (any, error) { return ruleIn(args[1], args[0]), nil }},
+	"lower":    {1, func(env *ruleEnv, args []any) (any, error) { return strings.ToLower(gconv.String(args[0])), nil }},
+	"upper":    {1, func(env *ruleEnv, args []any) (any, error) { return strings.ToUpper(gconv.String(args[0])), nil }},
+}
+
+// ---- values ----
+
+func ruleBool(v any) (bool, error) {
+	switch b := v.(type) {
+	case nil:
+		return false, nil
+	case bool:
+		return b, nil
+	}
+	return false, fmt.Errorf("%v is not bool", v)
+}
+
+func ruleNumber(v any) (float64, bool) {
+	switch reflect.ValueOf(v).Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return gconv.Float64(v), true
+	}
+	return 0, false
+}
+
+func ruleEqual(l, r any) bool {
+	if l == nil || r == nil {
+		return l == nil && r == nil
+	}
+	if ln, ok := ruleNumber(l); ok {
+		rn, ok := ruleNumber(r)
+		return ok && ln == rn
+	}
+	switch lv := l.(type) {
+	case string:
+		rv, ok := r.(string)
+		return ok && lv == rv
+	case bool:
+		rv, ok := r.(bool)
+		return ok && lv == rv
+	}
+	return reflect.DeepEqual(l, r)
+}
+
+// ruleIn v 是否为列表 list 中的元素; list 不是列表 (如字符串) 时为 false
+func ruleIn(v any, list any) bool {
+	rv := reflect.ValueOf(list)
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		return false
+	}
+	for i := 0; i < rv.Len(); i++ {
+		if ruleEqual(v, rv.Index(i).Interface()) {
+			return true
+		}
+	}
+	return false
+}
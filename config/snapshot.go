@@ -0,0 +1,213 @@
+package config
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/samber/lo"
+)
+
+// Snapshot 加载生成的配置快照, 生成后不再修改
+// 请求开始时获取, 请求过程中一直使用同一快照, 不受之后的 Reload 影响
+type Snapshot struct {
+	access         *Access
+	accessList     []AccessConfig
+	requestList    []RequestConfig
+	requestConfigs *RequestConfigs
+	dbMeta         *DBMeta
+
+	queryConfig  *QueryConfig
+	actionConfig *ActionConfig
+
+	LoadedAt time.Time
+}
+
+// Access 快照中的权限配置, 只读; 函数与 NoVerify 请读取 Config.Access
+func (s *Snapshot) Access() *Access {
+	return s.access
+}
+
+// AccessList 快照中的 _access 列表, 只读
+func (s *Snapshot) AccessList() []AccessConfig {
+	return s.accessList
+}
+
+// RequestList 快照中的 _request 列表, 只读
+func (s *Snapshot) RequestList() []RequestConfig {
+	return s.requestList
+}
+
+func (s *Snapshot) DbMeta() *DBMeta {
+	return s.dbMeta
+}
+
+func (s *Snapshot) QueryConfig() *QueryConfig {
+	return s.queryConfig
+}
+
+func (s *Snapshot) ActionConfig() *ActionConfig {
+	return s.actionConfig
+}
+
+// clone 复制 Access 的角色与 _access 用于快照, 之后对原 Access 的修改不影响快照
+// 函数与 NoVerify 不复制, 仍从原 Access 读取
+func (a *Access) clone() *Access {
+	access := Access{live: a.hooks()}
+	access.roleList = append([]string{}, a.roleList...)
+	access.roleInherits = map[string][]string{}
+	for role, inherits := range a.roleInherits {
+		access.roleInherits[role] = append([]string{}, inherits...)
+	}
+	return &access
+}
+
+// ReloadEvery 每隔 interval 重新加载配置, 用于轮询数据库中的 _access/_request, ctx 结束时停止
+// 加载失败时保留当前快照, 并调用 onErr
+func (c *Config) ReloadEvery(ctx context.Context, interval time.Duration, onErr func(err error)) {
+	ticker := time.NewTicker(interval)
+	changed := make(chan struct{})
+
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				select {
+				case changed <- struct{}{}:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	c.ReloadOn(ctx, changed, onErr)
+}
+
+// ReloadOn 每次从 changed 收到通知时重新加载配置, ctx 结束时停止
+// 加载失败时保留当前快照, 并调用 onErr
+func (c *Config) ReloadOn(ctx context.Context, changed <-chan struct{}, onErr func(err error)) {
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case _, ok := <-changed:
+				if !ok {
+					return
+				}
+				if err := c.Reload(ctx); err != nil && onErr != nil {
+					onErr(err)
+				}
+			}
+		}
+	}()
+}
+
+// watchDebounce 文件连续变化时, 最后一次变化后等待的时间
+const watchDebounce = 100 * time.Millisecond
+
+// WatchFiles 监听文件或目录 (包含子目录) 的变化, 返回的 chan 可用于 ReloadOn
+// 连续的多次变化只通知一次, ctx 结束时停止监听
+func WatchFiles(ctx context.Context, paths ...string) (<-chan struct{}, error) {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+
+	w := &fileWatcher{watcher: watcher, dirs: map[string]bool{}, files: map[string]bool{}}
+	for _, path := range lo.Uniq(paths) {
+		err = w.add(path)
+		if err != nil {
+			watcher.Close()
+			return nil, err
+		}
+	}
+
+	changed := make(chan struct{}, 1)
+
+	go func() {
+		defer watcher.Close()
+
+		debounce := time.NewTimer(watchDebounce)
+		debounce.Stop()
+		defer debounce.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				if w.match(event) {
+					debounce.Reset(watchDebounce)
+				}
+			case _, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+			case <-debounce.C:
+				select {
+				case changed <- struct{}{}:
+				default:
+				}
+			}
+		}
+	}()
+
+	return changed, nil
+}
+
+type fileWatcher struct {
+	watcher *fsnotify.Watcher
+	dirs    map[string]bool // 监听的目录, 包含子目录
+	files   map[string]bool // 监听的文件, 通过所在目录监听, 文件被替换 (如编辑器保存) 时仍有效
+}
+
+func (w *fileWatcher) add(path string) error {
+	path = filepath.Clean(path)
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+
+	if !info.IsDir() {
+		w.files[path] = true
+		return w.watcher.Add(filepath.Dir(path))
+	}
+
+	return filepath.Walk(path, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() {
+			return nil
+		}
+		w.dirs[path] = true
+		return w.watcher.Add(path)
+	})
+}
+
+// match 事件是否为监听的文件或目录中的变化, 新建的子目录加入监听
+func (w *fileWatcher) match(event fsnotify.Event) bool {
+	name := filepath.Clean(event.Name)
+	if w.files[name] {
+		return true
+	}
+	if !w.dirs[filepath.Dir(name)] {
+		return false
+	}
+	if event.Op&fsnotify.Create != 0 {
+		if info, err := os.Stat(name); err == nil && info.IsDir() {
+			_ = w.add(name)
+		}
+	}
+	return true
+}
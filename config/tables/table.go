@@ -24,6 +24,15 @@ type Access struct {
 	FieldsGet           map[string]any `ddl:"type:json;comment:get查询时字段配置"`
 	RowKeyGen           string         `ddl:"comment:rowKey生成策略"`
 	Executor            string         `ddl:"size:32;comment:执行器"`
+	VersionColumn       string         `ddl:"size:32;comment:乐观锁版本字段"`
+	Audit               int8           `ddl:"not null;default:0;comment:是否记录审计日志"`
+	SoftDeleteColumn    string         `ddl:"size:32;comment:软删除字段"`
+	AutoFill            map[string]any `ddl:"type:json;comment:自动填充字段"`
+	FieldsPost          map[string]any `ddl:"type:json;comment:各角色post时可写入的字段"`
+	FieldsPut           map[string]any `ddl:"type:json;comment:各角色put时可写入的字段"`
+	RowPolicy           map[string]any `ddl:"type:json;comment:行级权限策略"`
+	Rules               []any          `ddl:"type:json;comment:属性访问规则"`
+	TenantColumn        string         `ddl:"size:32;comment:租户字段"`
 }
 
 type Request struct {
@@ -40,6 +49,20 @@ type Request struct {
 	Executor            map[string]any `ddl:"type:json;comment:节点执行器,格式为Tag:executor;Tag2:executor 未配置为default"`
 }
 
+type Audit struct {
+	tablesync.TableMeta `tableName:"_audit" charset:"utf8mb4" comment:"写入审计日志"`
+	Id                  uint64         `ddl:"primaryKey"`
+	TableName           string         `ddl:"size:32;not null;comment:表名"`
+	RowKey              string         `ddl:"size:64;comment:行主键"`
+	Role                string         `ddl:"size:32;comment:角色"`
+	Method              string         `ddl:"size:6;not null;comment:请求方式"`
+	Before              map[string]any `ddl:"type:json;comment:修改前"`
+	After               map[string]any `ddl:"type:json;comment:修改后"`
+	Tag                 string         `ddl:"size:32;comment:请求标签"`
+	Version             string         `ddl:"size:8;comment:请求版本号"`
+	CreatedAt           *time.Time     `ddl:"NOT NULL;comment:创建时间"`
+}
+
 type Function struct {
 	tablesync.TableMeta `tableName:"_function" charset:"utf8mb4" comment:"远程函数(暂未使用)"`
 	Id                  uint32     `ddl:"primaryKey"`
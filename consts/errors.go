@@ -7,6 +7,12 @@ var (
 		code:    400,
 		message: "no tag",
 	}
+
+	// ErrNotLoaded 配置未加载, 需先调用 Load
+	ErrNotLoaded = Err{
+		code:    500,
+		message: "config not loaded",
+	}
 )
 
 func NewStructureKeyNoFoundErr(k string) Err {
@@ -57,6 +63,28 @@ func NewAccessNoFoundErr(key string) Err {
 	}
 }
 
+func NewRowNoFoundErr(key string) Err {
+	return Err{
+		code:    404,
+		message: "row no found: " + key,
+	}
+}
+
+func NewVersionConflictErr(key string) Err {
+	return Err{
+		code:    409,
+		message: "version conflict: " + key,
+	}
+}
+
+// NewErr 指定错误码的错误, 如远程接口返回的错误
+func NewErr(code int, msg string) Err {
+	return Err{
+		code:    code,
+		message: msg,
+	}
+}
+
 func NewSysErr(msg string) Err {
 	return Err{
 		code:    500,
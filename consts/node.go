@@ -9,7 +9,8 @@ const (
 
 const (
 	RowKey = "rowKey"
-	Raw    = "@raw"
+	Raw    = "@raw"    // 原始条件 map[string]any, 同 gdb 的 Where(map)
+	RawAnd = "@rawAnd" // 与 Raw 中字段重复的原始条件 []map[string]any, 逐个 AND
 )
 
 const (
@@ -23,8 +24,27 @@ const (
 	Column        = "@column"
 	Tag           = "tag"
 	Version       = "version"
+	Return        = "@return"  // 写入后按列表回查写入的行
+	Deleted       = "@deleted" // 包含软删除的行, 仅ADMIN可用
 )
 
+// action 返回值
+const (
+	Id           = "id"
+	IdList       = "id[]"
+	CountList    = "count[]"    // 批量修改/删除时每行的影响行数
+	InsertedList = "inserted[]" // upsert 时每行是否为新增
+)
+
+// 配置中的动态值
+const (
+	ValueNow       = "$now"  // 当前时间
+	CtxValuePrefix = "$ctx." // 上下文中的值, 如 $ctx.userId
+)
+
+// MethodUpsert 不存在时就添加, 存在时就修改
+const MethodUpsert = "UPSERT"
+
 const (
 	OpLike   = "$"
 	OpIn     = "{}"
@@ -0,0 +1,291 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/glennliao/apijson-go"
+	"github.com/glennliao/apijson-go/config"
+	"github.com/glennliao/apijson-go/consts"
+	"github.com/gogf/gf/v2/util/gconv"
+	"github.com/samber/lo"
+)
+
+// 配置目录中 _access 与 _request 所在的子目录, 每个文件为一个 access 或一个 request 的 tag/version
+var (
+	AccessDir    = "access"
+	RequestDir   = "request"
+	ProviderName = "file"
+)
+
+// requestFile request 配置文件, 一个文件为一个 tag 的一个版本, Methods 中为各请求方式的配置
+type requestFile struct {
+	Tag     string // 为空时使用文件名
+	Version string // 为空时为 1
+	Detail  string
+	Methods map[string]*requestMethod
+}
+
+type requestMethod struct {
+	Debug       int8
+	Detail      string
+	Structure   map[string]*config.Structure
+	ExecQueue   []string
+	Executor    map[string]string
+	Transaction *bool
+}
+
+var requestMethods = []string{http.MethodPost, http.MethodPut, http.MethodDelete, consts.MethodUpsert}
+
+// LoadAccessList 读取 dir/access 中的配置文件, 一个文件为一个 access, Alias 为空时使用文件名
+func LoadAccessList(dir string) ([]config.AccessConfig, error) {
+	paths, err := listFiles(filepath.Join(dir, AccessDir))
+	if err != nil {
+		return nil, err
+	}
+
+	var accessList []config.AccessConfig
+	aliasFile := map[string]string{}
+
+	for _, path := range paths {
+		f, err := parseFile(path)
+		if err != nil {
+			return nil, err
+		}
+
+		err = checkNode(f, f.root, reflect.TypeOf(config.AccessConfig{}), "")
+		if err != nil {
+			return nil, err
+		}
+
+		var access config.AccessConfig
+		err = decode(f, &access)
+		if err != nil {
+			return nil, err
+		}
+
+		if access.Name == "" {
+			return nil, f.errorf(f.root, "name required")
+		}
+		if access.Alias == "" {
+			access.Alias = f.stem()
+		}
+		access.Source = f.source(nil)
+
+		aliasKey, _ := mappingValue(f.root, "alias")
+		if other, exists := aliasFile[access.Alias]; exists {
+			return nil, f.errorf(aliasKey, "duplicate alias %s, also in %s", access.Alias, other)
+		}
+		aliasFile[access.Alias] = f.path
+
+		err = config.CheckAccessConfig(access)
+		if err != nil {
+			var fieldErr *config.AccessConfigError
+			if errors.As(err, &fieldErr) {
+				key, _ := mappingValue(f.root, fieldErr.Field)
+				return nil, f.errorf(key, "%s", fieldErr)
+			}
+			return nil, f.errorf(nil, "%s", err)
+		}
+
+		accessList = append(accessList, access)
+	}
+
+	return accessList, nil
+}
+
+// LoadRequestList 读取 dir/request 中的配置文件, 一个文件为一个 tag 的一个版本
+func LoadRequestList(dir string) ([]config.RequestConfig, error) {
+	paths, err := listFiles(filepath.Join(dir, RequestDir))
+	if err != nil {
+		return nil, err
+	}
+
+	var requestList []config.RequestConfig
+	requestFiles := map[string]string{}
+
+	for _, path := range paths {
+		f, err := parseFile(path)
+		if err != nil {
+			return nil, err
+		}
+
+		err = checkNode(f, f.root, reflect.TypeOf(requestFile{}), "")
+		if err != nil {
+			return nil, err
+		}
+
+		var item requestFile
+		err = decode(f, &item)
+		if err != nil {
+			return nil, err
+		}
+
+		if item.Tag == "" {
+			item.Tag = f.stem()
+		}
+		if item.Version == "" {
+			item.Version = "1"
+		}
+
+		_, methodsNode := mappingValue(f.root, "methods")
+		if len(item.Methods) == 0 {
+			return nil, f.errorf(f.root, "methods required")
+		}
+
+		for i := 0; i+1 < len(methodsNode.Content); i += 2 {
+			methodKey, methodNode := methodsNode.Content[i], methodsNode.Content[i+1]
+			method := strings.ToUpper(methodKey.Value)
+			if !lo.Contains(requestMethods, method) {
+				return nil, f.errorf(methodKey, "methods: unknown method %s", methodKey.Value)
+			}
+
+			key := item.Tag + "@" + method + "@" + item.Version
+			if other, exists := requestFiles[key]; exists {
+				return nil, f.errorf(methodKey, "duplicate request %s, also in %s", key, other)
+			}
+			requestFiles[key] = f.path
+
+			m := item.Methods[methodKey.Value]
+			if m == nil {
+				m = &requestMethod{}
+			}
+
+			request := config.RequestConfig{
+				Debug:       m.Debug,
+				Version:     item.Version,
+				Method:      method,
+				Tag:         item.Tag,
+				Structure:   m.Structure,
+				Detail:      m.Detail,
+				ExecQueue:   m.ExecQueue,
+				Executor:    m.Executor,
+				Transaction: m.Transaction,
+				Source:      f.source(methodKey),
+			}
+			if request.Detail == "" {
+				request.Detail = item.Detail
+			}
+
+			if len(request.Structure) == 0 {
+				request.Structure = map[string]*config.Structure{item.Tag: {}}
+			}
+
+			_, queueNode := mappingValue(methodNode, "execQueue")
+			for j, node := range request.ExecQueue {
+				if _, exists := request.Structure[node]; !exists {
+					return nil, f.errorf(queueNode.Content[j], "methods.%s.execQueue: %s not in structure", methodKey.Value, node)
+				}
+			}
+
+			requestList = append(requestList, request)
+		}
+	}
+
+	// 同一请求的最后一个版本为 latest
+	sort.SliceStable(requestList, func(i, j int) bool {
+		return versionLess(requestList[i].Version, requestList[j].Version)
+	})
+
+	return requestList, nil
+}
+
+func versionLess(a, b string) bool {
+	na, errA := strconv.ParseFloat(a, 64)
+	nb, errB := strconv.ParseFloat(b, 64)
+	if errA == nil && errB == nil {
+		return na < nb
+	}
+	return a < b
+}
+
+func decode(f *file, v any) error {
+	var data any
+	err := f.root.Decode(&data)
+	if err != nil {
+		return f.errorf(nil, "%s", err)
+	}
+	err = gconv.Scan(data, v)
+	if err != nil {
+		return f.errorf(nil, "%s", err)
+	}
+	return nil
+}
+
+// AccessListProvider 读取 dir 中的 _access 配置, 出错时 panic (Reload 时返回错误)
+func AccessListProvider(dir string) config.AccessListProvider {
+	return func(ctx context.Context) []config.AccessConfig {
+		accessList, err := LoadAccessList(dir)
+		if err != nil {
+			panic(err)
+		}
+		return accessList
+	}
+}
+
+// RequestListProvider 读取 dir 中的 _request 配置, 出错时 panic (Reload 时返回错误)
+func RequestListProvider(dir string) config.RequestListProvider {
+	return func(ctx context.Context) []config.RequestConfig {
+		requestList, err := LoadRequestList(dir)
+		if err != nil {
+			panic(err)
+		}
+		return requestList
+	}
+}
+
+// Plugin 使用 Dir 中的配置文件作为 _access 与 _request; Watch 为 true 时, Start 后文件变化时自动 Reload
+type Plugin struct {
+	Dir   string
+	Watch bool
+	// Reload 出错时调用, 出错时保留原配置
+	OnErr func(err error)
+
+	apijson *apijson.ApiJson
+	cancel  context.CancelFunc
+}
+
+func (p *Plugin) Name() string {
+	return "file-config"
+}
+
+func (p *Plugin) Install(ctx context.Context, a *apijson.ApiJson) {
+	p.apijson = a
+	a.Contribute(&apijson.Contributions{
+		AccessListProviders:  map[string]config.AccessListProvider{ProviderName: AccessListProvider(p.Dir)},
+		RequestListProviders: map[string]config.RequestListProvider{ProviderName: RequestListProvider(p.Dir)},
+	})
+	a.Config().AccessListProvider = ProviderName
+	a.Config().RequestListProvider = ProviderName
+}
+
+func (p *Plugin) Start(ctx context.Context) error {
+	if !p.Watch {
+		return nil
+	}
+
+	// Start 的 ctx 可能随启动结束, 监听使用独立的 ctx, Stop 时结束
+	watchCtx, cancel := context.WithCancel(context.Background())
+	changed, err := config.WatchFiles(watchCtx, p.Dir)
+	if err != nil {
+		cancel()
+		return err
+	}
+	p.cancel = cancel
+	p.apijson.Config().ReloadOn(watchCtx, changed, p.OnErr)
+	return nil
+}
+
+func (p *Plugin) Stop(ctx context.Context) error {
+	if p.cancel != nil {
+		p.cancel()
+		p.cancel = nil
+	}
+	return nil
+}
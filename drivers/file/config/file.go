@@ -0,0 +1,241 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// file 解析后的配置文件, 不同格式统一为 yaml.Node, 用于检查与定位行号
+type file struct {
+	path string
+	root *yaml.Node
+}
+
+// exts 支持的配置文件格式
+var exts = []string{".yaml", ".yml", ".json", ".toml"}
+
+func (f *file) errorf(node *yaml.Node, format string, args ...any) error {
+	msg := fmt.Sprintf(format, args...)
+	return fmt.Errorf("%s: %s", f.source(node), msg)
+}
+
+// syntaxErrorf 语法错误, 定位到行与列; offset 为出错位置的字节偏移
+func (f *file) syntaxErrorf(content []byte, line int, offset int64, format string, args ...any) error {
+	lineStart := 0
+	for i := 1; i < line; i++ {
+		n := bytes.IndexByte(content[lineStart:], '\n')
+		if n < 0 {
+			break
+		}
+		lineStart += n + 1
+	}
+	column := int(offset) - lineStart + 1
+	if column < 1 {
+		column = 1
+	}
+	msg := fmt.Sprintf(format, args...)
+	return fmt.Errorf("%s:%d:%d: %s", f.path, line, column, msg)
+}
+
+// jsonSyntaxError 根节点之后还有内容
+type jsonSyntaxError struct {
+	msg    string
+	offset int64
+}
+
+func (e *jsonSyntaxError) Error() string {
+	return e.msg
+}
+
+// source 节点所在的文件与行号, 用于 Lint 时定位; toml 中的节点没有行号
+func (f *file) source(node *yaml.Node) string {
+	if node != nil && node.Line > 0 {
+		return fmt.Sprintf("%s:%d", f.path, node.Line)
+	}
+	return f.path
+}
+
+// stem 不含扩展名的文件名
+func (f *file) stem() string {
+	base := filepath.Base(f.path)
+	return strings.TrimSuffix(base, filepath.Ext(base))
+}
+
+// listFiles dir 中 (包含子目录) 支持格式的配置文件, dir 不存在时为空
+func listFiles(dir string) ([]string, error) {
+	var files []string
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) && path == dir {
+				return filepath.SkipDir
+			}
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+		ext := strings.ToLower(filepath.Ext(path))
+		for _, item := range exts {
+			if ext == item {
+				files = append(files, path)
+			}
+		}
+		return nil
+	})
+	return files, err
+}
+
+func parseFile(path string) (*file, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	f := &file{path: path}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		f.root, err = parseJson(content)
+		if err != nil {
+			var syntaxErr *json.SyntaxError
+			if errors.As(err, &syntaxErr) {
+				return nil, f.syntaxErrorf(content, lineAt(content, syntaxErr.Offset), syntaxErr.Offset, "%s", err)
+			}
+			var trailingErr *jsonSyntaxError
+			if errors.As(err, &trailingErr) {
+				return nil, f.syntaxErrorf(content, lineAt(content, trailingErr.offset), trailingErr.offset, "%s", err)
+			}
+			return nil, f.errorf(nil, "%s", err)
+		}
+
+	case ".toml":
+		var data map[string]any
+		_, err = toml.Decode(string(content), &data)
+		if err != nil {
+			var parseErr toml.ParseError
+			if errors.As(err, &parseErr) {
+				// 去掉 toml 自带的行号前缀
+				prefix := fmt.Sprintf("toml: line %d: ", parseErr.Position.Line)
+				if parseErr.LastKey != "" {
+					prefix = fmt.Sprintf("toml: line %d (last key %q): ", parseErr.Position.Line, parseErr.LastKey)
+				}
+				msg := strings.TrimPrefix(parseErr.Error(), prefix)
+				return nil, f.syntaxErrorf(content, parseErr.Position.Line, int64(parseErr.Position.Start), "%s", msg)
+			}
+			return nil, f.errorf(nil, "%s", err)
+		}
+		// toml 中的字段没有行号
+		f.root = &yaml.Node{}
+		err = f.root.Encode(data)
+		if err != nil {
+			return nil, f.errorf(nil, "%s", err)
+		}
+
+	default:
+		var doc yaml.Node
+		err = yaml.Unmarshal(content, &doc)
+		if err != nil {
+			return nil, f.errorf(nil, "%s", err)
+		}
+		if len(doc.Content) > 0 {
+			f.root = doc.Content[0]
+		}
+	}
+
+	if f.root == nil || f.root.Kind != yaml.MappingNode {
+		return nil, f.errorf(f.root, "expect object")
+	}
+
+	return f, nil
+}
+
+func lineAt(content []byte, offset int64) int {
+	if offset > int64(len(content)) {
+		offset = int64(len(content))
+	}
+	return bytes.Count(content[:offset], []byte("\n")) + 1
+}
+
+// parseJson 将 json 解析为 yaml.Node 并记录行号; yaml 不允许 tab 缩进, 故不直接使用 yaml 解析
+func parseJson(content []byte) (*yaml.Node, error) {
+	dec := json.NewDecoder(bytes.NewReader(content))
+	dec.UseNumber()
+
+	// 下一个 token 的位置
+	nextOffset := func() int64 {
+		offset := dec.InputOffset()
+		for offset < int64(len(content)) && strings.IndexByte(" \t\r\n,:", content[offset]) >= 0 {
+			offset++
+		}
+		return offset
+	}
+
+	var read func() (*yaml.Node, error)
+	read = func() (*yaml.Node, error) {
+		line := lineAt(content, nextOffset())
+		token, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+
+		node := &yaml.Node{Kind: yaml.ScalarNode, Line: line}
+
+		switch v := token.(type) {
+		case json.Delim:
+			if v == '{' {
+				node.Kind, node.Tag = yaml.MappingNode, "!!map"
+			} else {
+				node.Kind, node.Tag = yaml.SequenceNode, "!!seq"
+			}
+			for dec.More() {
+				if node.Kind == yaml.MappingNode {
+					key, err := read()
+					if err != nil {
+						return nil, err
+					}
+					node.Content = append(node.Content, key)
+				}
+				value, err := read()
+				if err != nil {
+					return nil, err
+				}
+				node.Content = append(node.Content, value)
+			}
+			if _, err = dec.Token(); err != nil {
+				return nil, err
+			}
+		case string:
+			node.Tag, node.Value = "!!str", v
+		case json.Number:
+			node.Tag, node.Value = "!!int", v.String()
+			if strings.ContainsAny(v.String(), ".eE") {
+				node.Tag = "!!float"
+			}
+		case bool:
+			node.Tag, node.Value = "!!bool", fmt.Sprint(v)
+		case nil:
+			node.Tag, node.Value = "!!null", "null"
+		}
+		return node, nil
+	}
+
+	root, err := read()
+	if err != nil {
+		return nil, err
+	}
+	// 根节点之后只允许空白
+	offset := nextOffset()
+	if _, err = dec.Token(); err != io.EOF {
+		return nil, &jsonSyntaxError{msg: "invalid content after top-level value", offset: offset}
+	}
+	return root, nil
+}
@@ -0,0 +1,167 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/gogf/gf/v2/os/gtime"
+	"gopkg.in/yaml.v3"
+)
+
+var gtimeType = reflect.TypeOf(gtime.Time{})
+
+// normalizeKey 字段名比较时忽略大小写与 _ -, 与 gconv 的匹配规则一致
+func normalizeKey(key string) string {
+	key = strings.ReplaceAll(key, "_", "")
+	key = strings.ReplaceAll(key, "-", "")
+	return strings.ToLower(key)
+}
+
+// structFields 结构体可配置的字段, 按字段名与 json tag 匹配
+func structFields(t reflect.Type) map[string]reflect.StructField {
+	fields := map[string]reflect.StructField{}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := strings.Split(field.Tag.Get("json"), ",")[0]
+		if !field.IsExported() || tag == "-" {
+			continue
+		}
+		fields[normalizeKey(field.Name)] = field
+		if tag != "" {
+			fields[normalizeKey(tag)] = field
+		}
+	}
+	return fields
+}
+
+// mappingValue 在 mapping 节点中按字段名查找值
+func mappingValue(node *yaml.Node, key string) (*yaml.Node, *yaml.Node) {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil, nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if normalizeKey(node.Content[i].Value) == normalizeKey(key) {
+			return node.Content[i], node.Content[i+1]
+		}
+	}
+	return nil, nil
+}
+
+// checkNode 按 t 的结构检查节点, 不允许未知的字段与不匹配的类型
+func checkNode(f *file, node *yaml.Node, t reflect.Type, path string) error {
+	if node.Kind == yaml.AliasNode {
+		node = node.Alias
+	}
+
+	if node.Kind == yaml.ScalarNode && node.Tag == "!!null" {
+		return nil
+	}
+
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	expect := func(kind string) error {
+		if path == "" {
+			return f.errorf(node, "expect %s", kind)
+		}
+		return f.errorf(node, "%s: expect %s", path, kind)
+	}
+
+	switch t.Kind() {
+	case reflect.Interface:
+		return nil
+
+	case reflect.Struct:
+		if t == gtimeType {
+			if node.Kind != yaml.ScalarNode {
+				return expect("time")
+			}
+			return nil
+		}
+
+		if node.Kind != yaml.MappingNode {
+			return expect("object")
+		}
+
+		fields := structFields(t)
+		seen := map[string]bool{}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			field, ok := fields[normalizeKey(key.Value)]
+			if !ok {
+				return f.errorf(key, "%s: unknown field", joinPath(path, key.Value))
+			}
+			if seen[field.Name] {
+				return f.errorf(key, "%s: duplicate field", joinPath(path, key.Value))
+			}
+			seen[field.Name] = true
+
+			err := checkNode(f, value, field.Type, joinPath(path, key.Value))
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+
+	case reflect.Map:
+		if node.Kind != yaml.MappingNode {
+			return expect("object")
+		}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			err := checkNode(f, value, t.Elem(), joinPath(path, key.Value))
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+
+	case reflect.Slice, reflect.Array:
+		if node.Kind != yaml.SequenceNode {
+			return expect("list")
+		}
+		for i, item := range node.Content {
+			err := checkNode(f, item, t.Elem(), fmt.Sprintf("%s[%d]", path, i))
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+
+	case reflect.String:
+		if node.Kind != yaml.ScalarNode {
+			return expect("string")
+		}
+		return nil
+
+	case reflect.Bool:
+		if node.Kind != yaml.ScalarNode || node.Tag != "!!bool" {
+			return expect("bool")
+		}
+		return nil
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if node.Kind != yaml.ScalarNode || node.Tag != "!!int" {
+			return expect("int")
+		}
+		return nil
+
+	case reflect.Float32, reflect.Float64:
+		if node.Kind != yaml.ScalarNode || (node.Tag != "!!int" && node.Tag != "!!float") {
+			return expect("number")
+		}
+		return nil
+	}
+
+	return expect(t.Kind().String())
+}
+
+func joinPath(path string, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
@@ -2,11 +2,15 @@ package config
 
 import (
 	"context"
+	"fmt"
+	"sort"
 	"strings"
 
 	"github.com/glennliao/apijson-go/config"
+	"github.com/gogf/gf/v2/database/gdb"
 	"github.com/gogf/gf/v2/frame/g"
 	"github.com/gogf/gf/v2/util/gconv"
+	"github.com/samber/lo"
 )
 
 // 设置 _access/_request 自定义表名
@@ -83,21 +87,119 @@ func DbMetaProvider(ctx context.Context) []config.Table {
 			panic(err)
 		}
 
-		var columns []config.Column
-		for field, _ := range fields {
-			columns = append(columns, config.Column{Name: field})
+		fieldList := lo.Values(fields)
+		sort.Slice(fieldList, func(i, j int) bool {
+			return fieldList[i].Index < fieldList[j].Index
+		})
+
+		t := config.Table{Name: table}
+
+		for _, field := range fieldList {
+			t.Columns = append(t.Columns, config.Column{
+				Name:          field.Name,
+				Type:          field.Type,
+				Nullable:      field.Null,
+				Default:       field.Default,
+				AutoIncrement: strings.Contains(strings.ToLower(field.Extra), "auto_increment"),
+				Comment:       field.Comment,
+			})
+
+			switch strings.ToUpper(field.Key) {
+			case "PRI":
+				t.PrimaryKey = append(t.PrimaryKey, field.Name)
+			case "UNI":
+				t.Indexes = append(t.Indexes, config.Index{Name: field.Name, Columns: []string{field.Name}, Unique: true})
+			}
 		}
 
-		_tables = append(_tables, config.Table{
-			Name:    table,
-			Columns: columns,
-		})
+		// TableFields 中只有单字段的索引信息, 支持的数据库中读取完整的索引与表注释
+		err = tableIndexes(ctx, db, &t)
+		if err != nil {
+			panic(err)
+		}
 
+		_tables = append(_tables, t)
 	}
 
 	return _tables
 }
 
+func tableIndexes(ctx context.Context, db gdb.DB, t *config.Table) error {
+	switch db.GetConfig().Type {
+	case "mysql", "mariadb", "tidb":
+		rows, err := db.GetAll(ctx, "SHOW INDEX FROM `"+t.Name+"`")
+		if err != nil {
+			return err
+		}
+		var primaryKey []string
+		var indexes []config.Index
+		for _, row := range rows {
+			name, column := row["Key_name"].String(), row["Column_name"].String()
+			if name == "PRIMARY" {
+				primaryKey = append(primaryKey, column)
+				continue
+			}
+			indexes = addIndexColumn(indexes, name, column, !row["Non_unique"].Bool())
+		}
+		t.PrimaryKey, t.Indexes = primaryKey, indexes
+
+		comment, err := db.GetValue(ctx, "SELECT TABLE_COMMENT FROM information_schema.TABLES WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = ?", t.Name)
+		if err != nil {
+			return err
+		}
+		t.Comment = comment.String()
+
+	case "sqlite":
+		// 联合主键按 pk 中的顺序
+		info, err := db.GetAll(ctx, fmt.Sprintf("PRAGMA TABLE_INFO(`%s`)", t.Name))
+		if err != nil {
+			return err
+		}
+		sort.SliceStable(info, func(i, j int) bool {
+			return info[i]["pk"].Int() < info[j]["pk"].Int()
+		})
+		var primaryKey []string
+		for _, row := range info {
+			if row["pk"].Int() > 0 {
+				primaryKey = append(primaryKey, row["name"].String())
+			}
+		}
+		t.PrimaryKey = primaryKey
+
+		list, err := db.GetAll(ctx, fmt.Sprintf("PRAGMA INDEX_LIST(`%s`)", t.Name))
+		if err != nil {
+			return err
+		}
+		var indexes []config.Index
+		for _, index := range list {
+			if index["origin"].String() == "pk" {
+				continue
+			}
+			name := index["name"].String()
+			columns, err := db.GetAll(ctx, fmt.Sprintf("PRAGMA INDEX_INFO(`%s`)", name))
+			if err != nil {
+				return err
+			}
+			for _, column := range columns {
+				indexes = addIndexColumn(indexes, name, column["name"].String(), index["unique"].Bool())
+			}
+		}
+		t.Indexes = indexes
+	}
+
+	return nil
+}
+
+func addIndexColumn(indexes []config.Index, name string, column string, unique bool) []config.Index {
+	for i := range indexes {
+		if indexes[i].Name == name {
+			indexes[i].Columns = append(indexes[i].Columns, column)
+			return indexes
+		}
+	}
+	return append(indexes, config.Index{Name: name, Columns: []string{column}, Unique: unique})
+}
+
 func AccessListDBProvider(ctx context.Context) []config.AccessConfig {
 	// access
 	var accessList []config.AccessConfig
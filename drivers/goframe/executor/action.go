@@ -2,7 +2,10 @@ package executor
 
 import (
 	"context"
+	"fmt"
 	"net/http"
+	"reflect"
+	"sort"
 	"strings"
 
 	"github.com/glennliao/apijson-go/action"
@@ -12,6 +15,8 @@ import (
 	"github.com/gogf/gf/v2/database/gdb"
 	"github.com/gogf/gf/v2/frame/g"
 	"github.com/gogf/gf/v2/util/gconv"
+	"github.com/gogf/gf/v2/util/gutil"
+	"github.com/samber/lo"
 )
 
 type ActionExecutor struct {
@@ -21,84 +26,116 @@ type ActionExecutor struct {
 func (a *ActionExecutor) Do(ctx context.Context, req action.ActionExecutorReq) (ret model.Map, err error) {
 	switch req.Method {
 	case http.MethodPost:
-		return a.Insert(ctx, req.Table, req.Data)
+		return a.Insert(ctx, req.Table, req.Access.RowKeys(), req.Data)
 	case http.MethodPut:
+		if rowKeys := req.Access.RowKeys(); len(req.Data) > 1 && batchUpdatable(rowKeys, req.Data, req.Where) {
+			return a.BatchUpdate(ctx, req.Table, rowKeys, req.Data, req.Where)
+		}
 
-		for i, _ := range req.Data {
-			ret, err = a.Update(ctx, req.Table, req.Data[i], req.Where[i])
-			if err != nil {
-				break
-			}
+		return eachRow(len(req.Data), func(i int) (model.Map, error) {
+			return a.Update(ctx, req.Table, req.Data[i], req.Where[i])
+		})
+
+	case http.MethodDelete:
+		return eachRow(len(req.Where), func(i int) (model.Map, error) {
+			return a.Delete(ctx, req.Table, req.Where[i])
+		})
+
+	case consts.MethodUpsert:
+		// mysql 的 ON DUPLICATE KEY UPDATE 不能指定冲突字段, 任一唯一键冲突都会修改, 可能修改到其他租户的行
+		if req.Access.TenantColumn != "" && g.DB(a.DbName).GetConfig().Type == "mysql" {
+			return nil, consts.NewNoAccessErr(req.Access.Alias+"."+consts.MethodUpsert, "")
 		}
+		return a.Upsert(ctx, req.Table, req.Access.RowKeys(), req.Data, req.Conflict, req.ConflictUpdate, req.ConflictReplace)
+	}
+	return nil, consts.NewMethodNotSupportErr(req.Method)
+}
+
+// Insert 新增数据, 返回每一行的rowKey, 联合主键时为 字段->值
+// 已传入rowKey(如RowKeyGen生成)时直接使用; 否则逐行新增, 支持RETURNING的数据库通过RETURNING获取, 其他数据库获取自增id
+func (a *ActionExecutor) Insert(ctx context.Context, table string, rowKeys []string, data []model.Map) (ret model.Map, err error) {
+	db := g.DB(a.DbName)
+
+	var ids []any
+	var count int64
+
+	switch {
+	case len(rowKeys) == 0 || hasRowKey(rowKeys, data):
+		result, err := db.Insert(ctx, table, data)
 		if err != nil {
-			ret = model.Map{
-				"code": 200,
-			}
+			return nil, err
 		}
-		return ret, err
 
-	case http.MethodDelete:
+		count, err = result.RowsAffected()
+		if err != nil {
+			return nil, err
+		}
 
-		for i, _ := range req.Data {
-			ret, err = a.Delete(ctx, req.Table, req.Where[i])
+		if len(rowKeys) > 0 {
+			for _, item := range data {
+				ids = append(ids, rowKeyValue(rowKeys, item))
+			}
+		} else {
+			id, err := result.LastInsertId()
 			if err != nil {
-				break
+				return nil, err
 			}
+			ids = append(ids, id)
 		}
+
+	case supportReturning(db):
+		ids, err = insertReturning(ctx, db, table, rowKeys, data)
 		if err != nil {
-			ret = model.Map{
-				"code": 200,
-			}
+			return nil, err
 		}
-		return ret, err
-	}
-	return nil, consts.NewMethodNotSupportErr(req.Method)
-}
+		count = int64(len(ids))
 
-func (a *ActionExecutor) Insert(ctx context.Context, table string, data []model.Map) (ret model.Map, err error) {
-	result, err := g.DB(a.DbName).Insert(ctx, table, data)
-	if err != nil {
-		return nil, err
-	}
-	id, err := result.LastInsertId()
-	if err != nil {
-		return nil, err
-	}
+	case len(rowKeys) > 1:
+		// 联合主键无法通过自增id获取, 不返回id
+		result, err := db.Insert(ctx, table, data)
+		if err != nil {
+			return nil, err
+		}
+		count, err = result.RowsAffected()
+		if err != nil {
+			return nil, err
+		}
 
-	count, err := result.RowsAffected()
+	default:
+		for _, item := range data {
+			result, err := db.Insert(ctx, table, item)
+			if err != nil {
+				return nil, err
+			}
+			id, err := result.LastInsertId()
+			if err != nil {
+				return nil, err
+			}
+			ids = append(ids, id)
+			count++
+		}
+	}
 
 	ret = model.Map{
 		"code":  200,
 		"count": count,
-		"id":    id,
+	}
+
+	if len(ids) > 0 {
+		ret[consts.Id] = ids[0]
+	}
+
+	if len(data) > 1 && len(ids) == len(data) {
+		ret[consts.IdList] = ids
 	}
 
 	return ret, nil
 }
 
 func (a *ActionExecutor) Update(ctx context.Context, table string, data model.Map, where model.Map) (ret model.Map, err error) {
-	m := g.DB(a.DbName).Model(table).Ctx(ctx)
-
-	for k, v := range where {
-		if strings.HasSuffix(k, consts.OpIn) {
-			if vStr, ok := v.(string); ok {
-				if vStr == "" {
-					return nil, consts.NewValidReqErr("where的值不能为空")
-				}
-			}
-			m = m.WhereIn(k[0:len(k)-2], v)
-			delete(where, k)
-			continue
-		}
-		if k == consts.Raw {
-			m = m.Where(v.(map[string]any))
-			delete(where, k)
-			continue
-		}
-
-		if v == nil || gconv.String(v) == "" { // 暂只处理字符串为空的情况
-			return nil, consts.NewValidReqErr("where的值不能为空:" + k)
-		}
+	m, err := whereModel(g.DB(a.DbName).Model(table).Ctx(ctx), where)
+	if err != nil {
+		return nil, err
 	}
 
 	for k, v := range data {
@@ -122,7 +159,7 @@ func (a *ActionExecutor) Update(ctx context.Context, table string, data model.Ma
 		}
 	}
 
-	_ret, err := m.Where(where).Update(data)
+	_ret, err := m.Update(data)
 	if err != nil {
 		return nil, err
 	}
@@ -140,48 +177,636 @@ func (a *ActionExecutor) Update(ctx context.Context, table string, data model.Ma
 	return ret, err
 }
 
+// BatchUpdate 将按rowKey逐行不同的修改合并为一条 UPDATE ... SET field = CASE WHEN rowKey=? THEN ... END 语句
+// count[] 为每行命中 (matched) 的行数, 包括值未变化的行; 不在事务中时开启事务, 查询命中的行与修改在同一事务中
+func (a *ActionExecutor) BatchUpdate(ctx context.Context, table string, rowKeys []string, data []model.Map, where []model.Map) (ret model.Map, err error) {
+	db := g.DB(a.DbName)
+	if gdb.TXFromCtx(ctx, db.GetGroup()) != nil {
+		return batchUpdate(ctx, db, table, rowKeys, data, where)
+	}
+
+	err = db.Transaction(ctx, func(ctx context.Context, tx gdb.TX) error {
+		ret, err = batchUpdate(ctx, db, table, rowKeys, data, where)
+		return err
+	})
+	return ret, err
+}
+
+func batchUpdate(ctx context.Context, db gdb.DB, table string, rowKeys []string, data []model.Map, where []model.Map) (ret model.Map, err error) {
+	core := db.GetCore()
+
+	tuples := make([][]any, len(where))
+	common := model.Map{}
+	for i, item := range where {
+		for _, key := range rowKeys {
+			tuples[i] = append(tuples[i], item[key])
+		}
+	}
+	for k, v := range where[0] {
+		if !lo.Contains(rowKeys, k) {
+			common[k] = v
+		}
+	}
+
+	newModel := func() (*gdb.Model, error) {
+		m, err := whereModel(db.Model(table).Ctx(ctx), common)
+		if err != nil {
+			return nil, err
+		}
+		return whereRowKeys(m, rowKeys, tuples), nil
+	}
+
+	// 事务内先查询命中的行, mysql 中加锁
+	m, err := newModel()
+	if err != nil {
+		return nil, err
+	}
+	if db.GetConfig().Type == "mysql" {
+		m = m.LockUpdate()
+	}
+	matched, err := m.Fields(rowKeys).All()
+	if err != nil {
+		return nil, err
+	}
+
+	matchedSet := map[string]bool{}
+	for _, record := range matched {
+		matchedSet[conflictKey(rowKeys, model.Map(record.Map()))] = true
+	}
+
+	var cond []string
+	for _, key := range rowKeys {
+		cond = append(cond, core.QuoteWord(key)+"=?")
+	}
+	when := " WHEN " + strings.Join(cond, " AND ") + " THEN ?"
+
+	var (
+		sets   []string
+		params []any
+	)
+	for _, field := range sortedFields(data[0]) {
+		set := core.QuoteWord(field) + "=CASE"
+		for i, item := range data {
+			val, err := core.ConvertDataForRecordValue(ctx, item[field])
+			if err != nil {
+				return nil, err
+			}
+			set += when
+			params = append(append(params, tuples[i]...), val)
+		}
+		sets = append(sets, set+" ELSE "+core.QuoteWord(field)+" END")
+	}
+
+	m, err = newModel()
+	if err != nil {
+		return nil, err
+	}
+	_, err = m.Data(append([]any{strings.Join(sets, ",")}, params...)...).Update()
+	if err != nil {
+		return nil, err
+	}
+
+	var total int64
+	counts := make([]int64, len(where))
+	for i, item := range where {
+		if matchedSet[conflictKey(rowKeys, item)] {
+			counts[i] = 1
+			total++
+		}
+	}
+
+	ret = model.Map{
+		"code":           200,
+		"count":          total,
+		consts.CountList: counts,
+	}
+
+	return ret, nil
+}
+
 func (a *ActionExecutor) Delete(ctx context.Context, table string, where model.Map) (ret model.Map, err error) {
 	if len(where) == 0 {
 		return nil, consts.NewValidReqErr("where的值不能为空")
 	}
 
-	m := g.DB(a.DbName).Model(table).Ctx(ctx)
+	m, err := whereModel(g.DB(a.DbName).Model(table).Ctx(ctx), where)
+	if err != nil {
+		return nil, err
+	}
 
-	for k, v := range where {
+	_ret, err := m.Delete()
+	if err != nil {
+		return nil, err
+	}
 
-		if k == consts.Raw {
-			m = m.Where(v)
+	count, err := _ret.RowsAffected()
+
+	if err != nil {
+		return nil, err
+	}
+
+	ret = model.Map{
+		"code":  200,
+		"count": count,
+	}
+
+	return ret, err
+}
+
+// whereModel 设置修改的where条件
+func whereModel(m *gdb.Model, where model.Map) (*gdb.Model, error) {
+	where = gutil.MapCopy(where)
+
+	for k, v := range where {
+		if strings.HasSuffix(k, consts.OpIn) && strings.Contains(k, ",") {
+			// 联合主键 a,b{}: [[1,2],[1,3]]
+			rowKeys := strings.Split(k[0:len(k)-2], ",")
+			var tuples [][]any
+			for _, item := range gconv.Interfaces(v) {
+				tuple := gconv.Interfaces(item)
+				if len(tuple) != len(rowKeys) {
+					return nil, consts.NewValidReqErr("联合主键的值数量不匹配:" + k)
+				}
+				tuples = append(tuples, tuple)
+			}
+			if len(tuples) == 0 {
+				return nil, consts.NewValidReqErr("where的值不能为空:" + k)
+			}
+			m = whereRowKeys(m, rowKeys, tuples)
+			delete(where, k)
 			continue
 		}
-
 		if strings.HasSuffix(k, consts.OpIn) {
+			if vStr, ok := v.(string); ok {
+				if vStr == "" {
+					return nil, consts.NewValidReqErr("where的值不能为空")
+				}
+			}
 			m = m.WhereIn(k[0:len(k)-2], v)
 			delete(where, k)
 			continue
 		}
+		if k == consts.Raw {
+			if raw, ok := v.(map[string]any); ok {
+				m = m.Where(raw)
+			}
+			delete(where, k)
+			continue
+		}
+		if k == consts.RawAnd {
+			rawAnd, _ := v.([]map[string]any)
+			for _, raw := range rawAnd {
+				m = m.Where(raw)
+			}
+			delete(where, k)
+			continue
+		}
 
-		if gconv.String(v) == "" || v == nil { // 暂只处理字符串为空的情况
+		if v == nil || gconv.String(v) == "" { // 暂只处理字符串为空的情况
 			return nil, consts.NewValidReqErr("where的值不能为空:" + k)
 		}
+	}
 
-		m = m.Where(k, v)
+	return m.Where(where), nil
+}
+
+// whereRowKeys 按主键值列表设置条件, 联合主键时为 (a=? AND b=?) OR ...
+func whereRowKeys(m *gdb.Model, rowKeys []string, tuples [][]any) *gdb.Model {
+	if len(rowKeys) == 1 {
+		ids := make([]any, len(tuples))
+		for i, tuple := range tuples {
+			ids[i] = tuple[0]
+		}
+		return m.WhereIn(rowKeys[0], ids)
 	}
 
-	_ret, err := m.Delete()
+	builder := m.Builder()
+	for _, tuple := range tuples {
+		where := g.Map{}
+		for i, key := range rowKeys {
+			where[key] = tuple[i]
+		}
+		builder = builder.WhereOr(m.Builder().Where(where))
+	}
+	return m.Where(builder)
+}
+
+// eachRow 逐行执行, 汇总每行及总的影响行数
+func eachRow(num int, do func(i int) (model.Map, error)) (model.Map, error) {
+	var total int64
+	counts := make([]int64, num)
+
+	for i := 0; i < num; i++ {
+		ret, err := do(i)
+		if err != nil {
+			return nil, err
+		}
+		counts[i] = gconv.Int64(ret["count"])
+		total += counts[i]
+	}
+
+	ret := model.Map{
+		"code":  200,
+		"count": total,
+	}
+	if num > 1 {
+		ret[consts.CountList] = counts
+	}
+	return ret, nil
+}
+
+// batchUpdatable 是否可合并为一条语句: 每行按完整的rowKey区分且rowKey不重复, 其余条件相同, 修改的字段相同且不含 +/- 运算
+func batchUpdatable(rowKeys []string, data []model.Map, where []model.Map) bool {
+	if len(rowKeys) == 0 || len(data) != len(where) || len(data[0]) == 0 {
+		return false
+	}
+
+	fields := sortedFields(data[0])
+	for _, field := range fields {
+		if strings.HasSuffix(field, consts.OpPLus) || strings.HasSuffix(field, consts.OpSub) {
+			return false
+		}
+	}
+
+	common := func(item model.Map) model.Map {
+		m := model.Map{}
+		for k, v := range item {
+			if !lo.Contains(rowKeys, k) {
+				m[k] = v
+			}
+		}
+		return m
+	}
+	where0 := common(where[0])
+
+	ids := map[string]bool{}
+	for i, item := range where {
+		for _, key := range rowKeys {
+			id, exists := item[key]
+			if !exists || id == nil || reflect.ValueOf(id).Kind() == reflect.Slice {
+				return false
+			}
+		}
+		id := conflictKey(rowKeys, item)
+		if ids[id] {
+			return false
+		}
+		ids[id] = true
+
+		if !reflect.DeepEqual(common(item), where0) {
+			return false
+		}
+		if !reflect.DeepEqual(sortedFields(data[i]), fields) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// hasRowKey 是否每一行都已有rowKey的值
+func hasRowKey(rowKeys []string, data []model.Map) bool {
+	for _, item := range data {
+		for _, key := range rowKeys {
+			if v, exists := item[key]; !exists || v == nil {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// rowKeyValue 行的rowKey值, 联合主键时为 字段->值
+func rowKeyValue(rowKeys []string, item model.Map) any {
+	if len(rowKeys) == 1 {
+		return item[rowKeys[0]]
+	}
+	val := model.Map{}
+	for _, key := range rowKeys {
+		val[key] = item[key]
+	}
+	return val
+}
+
+func supportReturning(db gdb.DB) bool {
+	switch db.GetConfig().Type {
+	case "pgsql", "sqlite":
+		return true
+	}
+	return false
+}
+
+// insertReturning 使用 INSERT ... RETURNING 逐行新增并返回rowKey
+// 多行 VALUES 时 RETURNING 的顺序不保证与请求一致, 无法对应到请求中的行, 故逐行执行; 未传入的字段使用默认值
+func insertReturning(ctx context.Context, db gdb.DB, table string, rowKeys []string, data []model.Map) ([]any, error) {
+	core := db.GetCore()
+
+	var ids []any
+	for _, item := range data {
+		fields := sortedFields(item)
+		holders, params, err := buildValues(ctx, core, fields, []model.Map{item})
+		if err != nil {
+			return nil, err
+		}
+
+		sql := fmt.Sprintf("INSERT INTO %s(%s) VALUES%s RETURNING %s",
+			core.QuotePrefixTableName(table), quoteFields(core, fields), holders, quoteFields(core, rowKeys))
+
+		record, err := db.GetOne(ctx, sql, params...)
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, rowKeyValue(rowKeys, record.Map()))
+	}
+	return ids, nil
+}
+
+// Upsert 不存在时新增, 存在时修改
+// conflict 为冲突判断字段(唯一键), update 为冲突时使用新值更新的字段, replace 为冲突时替换的值
+// 各行的字段需相同; 返回值中 count 为新增与修改的行数 (mysql 中值未变化的行不计入), inserted[] 为每一行是否为新增
+// 不在事务中时开启事务, 判断新增/修改的查询与写入在同一事务中
+func (a *ActionExecutor) Upsert(ctx context.Context, table string, rowKeys []string, data []model.Map, conflict []string, update []string, replace model.Map) (ret model.Map, err error) {
+	if len(data) == 0 {
+		return nil, consts.NewValidReqErr("upsert data is empty: " + table)
+	}
+
+	if len(conflict) == 0 {
+		return nil, consts.NewValidReqErr("upsert conflict field is empty: " + table)
+	}
+
+	for _, item := range data {
+		for _, field := range conflict {
+			if _, exists := item[field]; !exists {
+				return nil, consts.NewValidReqErr("upsert conflict field is required: " + table + "." + field)
+			}
+		}
+	}
+
+	fields := sortedFields(data[0])
+	if !sameFields(fields, data) {
+		return nil, consts.NewValidReqErr("upsert rows must have the same fields: " + table)
+	}
+
+	db := g.DB(a.DbName)
+	if gdb.TXFromCtx(ctx, db.GetGroup()) != nil {
+		return upsert(ctx, db, table, rowKeys, fields, data, conflict, update, replace)
+	}
+
+	err = db.Transaction(ctx, func(ctx context.Context, tx gdb.TX) error {
+		ret, err = upsert(ctx, db, table, rowKeys, fields, data, conflict, update, replace)
+		return err
+	})
+	return ret, err
+}
+
+// upsertInserted pgsql 中 RETURNING 的是否为新增的字段
+const upsertInserted = "apijson_inserted"
+
+func upsert(ctx context.Context, db gdb.DB, table string, rowKeys []string, fields []string, data []model.Map, conflict []string, update []string, replace model.Map) (ret model.Map, err error) {
+	core := db.GetCore()
+	dbType := db.GetConfig().Type
+
+	holders, params, err := buildValues(ctx, core, fields, data)
 	if err != nil {
 		return nil, err
 	}
 
-	count, err := _ret.RowsAffected()
+	var sets []string
+	for _, field := range update {
+		switch dbType {
+		case "mysql":
+			sets = append(sets, fmt.Sprintf("%s=VALUES(%s)", core.QuoteWord(field), core.QuoteWord(field)))
+		default:
+			sets = append(sets, fmt.Sprintf("%s=excluded.%s", core.QuoteWord(field), core.QuoteWord(field)))
+		}
+	}
+
+	replaceFields := sortedFields(replace)
+	for _, field := range replaceFields {
+		val, err := core.ConvertDataForRecordValue(ctx, replace[field])
+		if err != nil {
+			return nil, err
+		}
+		sets = append(sets, core.QuoteWord(field)+"=?")
+		params = append(params, val)
+	}
+
+	sql := fmt.Sprintf("INSERT INTO %s(%s) VALUES%s", core.QuotePrefixTableName(table), quoteFields(core, fields), holders)
+
+	switch dbType {
+	case "mysql":
+		if len(sets) == 0 {
+			sets = []string{core.QuoteWord(conflict[0]) + "=" + core.QuoteWord(conflict[0])}
+		}
+		sql += " ON DUPLICATE KEY UPDATE " + strings.Join(sets, ",")
+	case "pgsql", "sqlite":
+		sql += " ON CONFLICT(" + quoteFields(core, conflict) + ")"
+		if len(sets) == 0 {
+			sql += " DO NOTHING"
+		} else {
+			sql += " DO UPDATE SET " + strings.Join(sets, ",")
+		}
+	default:
+		return nil, consts.NewMethodNotSupportErr(consts.MethodUpsert + " not support: " + dbType)
+	}
+
+	var (
+		existed  map[string]gdb.Record // 写入前已存在的行
+		returned map[string]gdb.Record // pgsql 中新增或修改的行
+		count    int64
+	)
+
+	if dbType == "pgsql" {
+		// xmax 为 0 时为新增的行, 不需要预先查询
+		sql += fmt.Sprintf(" RETURNING %s, (xmax = 0) AS %s", quoteFields(core, lo.Union(rowKeys, conflict)), upsertInserted)
+		result, err := db.GetAll(ctx, sql, params...)
+		if err != nil {
+			return nil, err
+		}
+		returned = make(map[string]gdb.Record, len(result))
+		for _, record := range result {
+			returned[conflictKey(conflict, record.Map())] = record
+		}
+		count = int64(len(result))
+	} else {
+		// 事务中查询已存在的行, mysql 中加锁, sqlite 中其他写入在事务结束前无法提交
+		existed, err = selectByConflict(ctx, db, table, conflict, rowKeys, data, dbType == "mysql")
+		if err != nil {
+			return nil, err
+		}
+		result, err := db.Exec(ctx, sql, params...)
+		if err != nil {
+			return nil, err
+		}
+		count, err = result.RowsAffected()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var (
+		inserted     = make([]bool, len(data))
+		insertedNum  = 0
+		ids          []any
+		afterExisted map[string]gdb.Record
+	)
+
+	if len(rowKeys) > 0 && len(lo.Without(rowKeys, conflict...)) > 0 && len(returned) < len(data) {
+		afterExisted, err = selectByConflict(ctx, db, table, conflict, rowKeys, data, false)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for i, item := range data {
+		key := conflictKey(conflict, item)
+		record, isReturned := returned[key]
+		switch {
+		case returned != nil:
+			inserted[i] = isReturned && record[upsertInserted].Bool()
+		default:
+			if _, exists := existed[key]; !exists {
+				inserted[i] = true
+				existed[key] = nil // 同一批次中重复的行视为修改
+			}
+		}
+		if inserted[i] {
+			insertedNum++
+		}
+
+		if len(rowKeys) == 0 {
+			continue
+		}
+		if isReturned {
+			ids = append(ids, rowKeyValue(rowKeys, record.Map()))
+			continue
+		}
+		if afterExisted != nil {
+			if record, exists := afterExisted[key]; exists {
+				ids = append(ids, rowKeyValue(rowKeys, record.Map()))
+				continue
+			}
+		}
+		ids = append(ids, rowKeyValue(rowKeys, item))
+	}
+
+	// mysql 中修改的行计为 2
+	if dbType == "mysql" && count > int64(insertedNum) {
+		count = int64(insertedNum) + (count-int64(insertedNum))/2
+	}
+
+	ret = model.Map{
+		"code":              200,
+		"count":             count,
+		"inserted":          insertedNum,
+		"updated":           len(data) - insertedNum,
+		consts.InsertedList: inserted,
+	}
 
+	if len(ids) > 0 {
+		ret[consts.Id] = ids[0]
+	}
+	if len(data) > 1 && len(ids) == len(data) {
+		ret[consts.IdList] = ids
+	}
+
+	return ret, nil
+}
+
+// selectByConflict 按冲突字段查询已存在的行, 返回 冲突字段值 -> 行; lock 时使用 FOR UPDATE
+func selectByConflict(ctx context.Context, db gdb.DB, table string, conflict []string, rowKeys []string, data []model.Map, lock bool) (map[string]gdb.Record, error) {
+	fields := lo.Union(rowKeys, conflict)
+
+	m := db.Model(table).Ctx(ctx).Fields(fields)
+	if lock {
+		m = m.LockUpdate()
+	}
+
+	builder := m.Builder()
+	for _, item := range data {
+		where := g.Map{}
+		for _, field := range conflict {
+			where[field] = item[field]
+		}
+		builder = builder.WhereOr(m.Builder().Where(where))
+	}
+
+	result, err := m.Where(builder).All()
 	if err != nil {
 		return nil, err
 	}
 
-	ret = model.Map{
-		"code":  200,
-		"count": count,
+	existed := make(map[string]gdb.Record)
+	for _, record := range result {
+		item := model.Map{}
+		for _, field := range conflict {
+			item[field] = record[field].Val()
+		}
+		existed[conflictKey(conflict, item)] = record
 	}
 
-	return ret, err
+	return existed, nil
+}
+
+func conflictKey(conflict []string, item model.Map) string {
+	values := make([]string, len(conflict))
+	for i, field := range conflict {
+		values[i] = gconv.String(item[field])
+	}
+	return strings.Join(values, "\x00")
+}
+
+// sameFields 各行的字段是否均为 fields
+func sameFields(fields []string, data []model.Map) bool {
+	for _, item := range data {
+		if len(item) != len(fields) {
+			return false
+		}
+		for _, field := range fields {
+			if _, exists := item[field]; !exists {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func sortedFields(m model.Map) []string {
+	var fields []string
+	for k := range m {
+		fields = append(fields, k)
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+func quoteFields(core *gdb.Core, fields []string) string {
+	quoted := make([]string, len(fields))
+	for i, field := range fields {
+		quoted[i] = core.QuoteWord(field)
+	}
+	return strings.Join(quoted, ",")
+}
+
+// buildValues 生成批量写入的 (?,?),(?,?) 与参数
+func buildValues(ctx context.Context, core *gdb.Core, fields []string, data []model.Map) (string, []any, error) {
+	var (
+		holders []string
+		params  []any
+	)
+
+	for _, item := range data {
+		record, err := core.ConvertDataForRecord(ctx, map[string]any(item))
+		if err != nil {
+			return "", nil, err
+		}
+		values := make([]string, len(fields))
+		for i, field := range fields {
+			values[i] = "?"
+			params = append(params, record[field])
+		}
+		holders = append(holders, "("+strings.Join(values, ",")+")")
+	}
+
+	return strings.Join(holders, ","), params, nil
 }
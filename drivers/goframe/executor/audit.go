@@ -0,0 +1,72 @@
+package executor
+
+import (
+	"context"
+
+	"github.com/glennliao/apijson-go/action"
+	"github.com/glennliao/apijson-go/model"
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/util/gconv"
+)
+
+// FetchRows 按修改条件查询行, 用于审计获取修改前后的数据 (mysql/pgsql 中锁定查询的行)
+func (a *ActionExecutor) FetchRows(ctx context.Context, table string, where model.Map) ([]model.Map, error) {
+	db := g.DB(a.DbName)
+
+	m, err := whereModel(db.Model(table).Ctx(ctx), where)
+	if err != nil {
+		return nil, err
+	}
+
+	switch db.GetConfig().Type {
+	case "mysql", "pgsql":
+		m = m.LockUpdate()
+	}
+
+	all, err := m.All()
+	if err != nil {
+		return nil, err
+	}
+
+	var list []model.Map
+	for _, item := range all.List() {
+		list = append(list, item)
+	}
+	return list, nil
+}
+
+// AuditSink 将审计记录写入数据库表, 表结构见 tables.Audit
+type AuditSink struct {
+	DbName string
+	Table  string // 默认 _audit
+}
+
+func (s *AuditSink) Write(ctx context.Context, records []action.AuditRecord) error {
+	table := s.Table
+	if table == "" {
+		table = "_audit"
+	}
+
+	data := make([]model.Map, 0, len(records))
+	for _, record := range records {
+		var after any
+		if record.After != nil {
+			after = record.After
+		}
+
+		data = append(data, model.Map{
+			"table_name": record.Table,
+			"row_key":    gconv.String(record.RowKey),
+			"role":       record.Role,
+			"method":     record.Method,
+			"before":     record.Before,
+			"after":      after,
+			"tag":        record.Tag,
+			"version":    record.Version,
+			"created_at": record.CreatedAt,
+		})
+	}
+
+	_, err := g.DB(s.DbName).Model(table).Ctx(ctx).Data(data).Insert()
+	return err
+}
@@ -23,7 +23,7 @@ type SqlExecutor struct {
 
 	// 保存where条件 [ ["user_id",">", 123], ["user_id","<=",345] ]
 	Where           [][]any
-	accessCondition model.Map
+	accessCondition []map[string]any
 
 	Columns []string
 	Order   string
@@ -64,7 +64,13 @@ func (e *SqlExecutor) ParseCondition(conditions model.MapStrAny, accessVerify bo
 			e.Where = append(e.Where, []any{key[0 : len(key)-1], consts.SqlRegexp, gconv.String(condition)})
 
 		case key == consts.Raw && !accessVerify:
-			e.accessCondition = condition.(map[string]any)
+			if raw, ok := condition.(map[string]any); ok {
+				e.accessCondition = append(e.accessCondition, raw)
+			}
+
+		case key == consts.RawAnd && !accessVerify:
+			rawAnd, _ := condition.([]map[string]any)
+			e.accessCondition = append(e.accessCondition, rawAnd...)
 
 		default:
 			e.Where = append(e.Where, []any{key, consts.SqlEqual, condition})
@@ -154,7 +160,7 @@ func (e *SqlExecutor) parseMultiCondition(k string, condition any) {
 
 	switch k[len(k)-1] {
 	case '&', '|', '!':
-		e.Where = append(e.Where, []any{getK(k), k[len(k)-1], value})
+		e.Where = append(e.Where, []any{getK(k), string(k[len(k)-1]), value})
 	default:
 		e.Where = append(e.Where, []any{k, "in", value})
 
@@ -221,21 +227,21 @@ func (e *SqlExecutor) build() *gdb.Model {
 		if conditions, ok := value.([][]string); ok { // multiCondition
 
 			switch op {
-			case '&':
+			case "&":
 				b := m.Builder()
 				for _, c := range conditions {
 					b = b.Where(key+" "+c[0], c[1])
 				}
 				whereBuild = whereBuild.Where(b)
 
-			case '|':
+			case "|":
 				b := m.Builder()
 				for _, c := range conditions {
 					b = b.WhereOr(key+" "+c[0], c[1])
 				}
 				whereBuild = whereBuild.Where(b)
 
-			case '!':
+			case "!":
 				whereBuild = whereBuild.WhereNotIn(key, conditions)
 
 			default:
@@ -250,6 +256,8 @@ func (e *SqlExecutor) build() *gdb.Model {
 				whereBuild = whereBuild.Where(key+" "+consts.SqlRegexp, value.(string))
 			case "in":
 				whereBuild = whereBuild.WhereIn(key, value)
+			case "!":
+				whereBuild = whereBuild.WhereNotIn(key, value)
 			case consts.SqlEqual:
 				whereBuild = whereBuild.Where(key, value)
 			}
@@ -258,8 +266,8 @@ func (e *SqlExecutor) build() *gdb.Model {
 	}
 
 	m = m.Where(whereBuild)
-	if e.accessCondition != nil {
-		m = m.Where(e.accessCondition)
+	for _, raw := range e.accessCondition {
+		m = m.Where(raw)
 	}
 
 	if e.Group != "" {
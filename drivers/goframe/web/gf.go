@@ -10,6 +10,7 @@ import (
 	"github.com/glennliao/apijson-go"
 	"github.com/glennliao/apijson-go/consts"
 	"github.com/glennliao/apijson-go/model"
+	"github.com/glennliao/apijson-go/openapi"
 	"github.com/gogf/gf/v2/container/gmap"
 	"github.com/gogf/gf/v2/errors/gerror"
 	"github.com/gogf/gf/v2/frame/g"
@@ -55,6 +56,18 @@ func (gf *GF) Bind(group *ghttp.RouterGroup, mode ...Mode) {
 	group.POST("/head", gf.ResponseResolver(gf.Head, mode[0], gf.apijson.Debug))
 	group.POST("/put", gf.ResponseResolver(gf.Put, mode[0], gf.apijson.Debug))
 	group.POST("/delete", gf.ResponseResolver(gf.Delete, mode[0], gf.apijson.Debug))
+	group.POST("/upsert", gf.ResponseResolver(gf.Upsert, mode[0], gf.apijson.Debug))
+}
+
+// BindOpenApi 提供 GET /openapi.json 与 /schema.json (_request 的 JSON Schema), 按当前配置生成
+// 文档包含表结构与可访问的字段, 按需在需要鉴权的 group 中绑定
+func (gf *GF) BindOpenApi(group *ghttp.RouterGroup, opts openapi.Options) {
+	group.GET("/openapi.json", func(req *ghttp.Request) {
+		req.Response.WriteJson(openapi.Document(req.Context(), gf.apijson, opts))
+	})
+	group.GET("/schema.json", func(req *ghttp.Request) {
+		req.Response.WriteJson(openapi.RequestSchemas(req.Context(), gf.apijson, opts.Role))
+	})
 }
 
 func (gf *GF) Get(ctx context.Context, req model.Map) (res model.Map, err error) {
@@ -81,6 +94,11 @@ func (gf *GF) Delete(ctx context.Context, req model.Map) (res model.Map, err err
 	return act.Result()
 }
 
+func (gf *GF) Upsert(ctx context.Context, req model.Map) (res model.Map, err error) {
+	act := gf.apijson.NewAction(ctx, consts.MethodUpsert, req)
+	return act.Result()
+}
+
 // 调试模式开启, 使用orderedmap输出结果
 func sortMap(ctx context.Context, body []byte, res *gmap.ListMap, ret model.Map) *orderedmap.OrderedMap {
 	reqSortMap := orderedmap.New()
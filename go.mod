@@ -1,18 +1,19 @@
 module github.com/glennliao/apijson-go
 
 require (
+	github.com/BurntSushi/toml v1.1.0
+	github.com/fsnotify/fsnotify v1.5.4
 	github.com/glennliao/table-sync v0.2.1
 	github.com/gogf/gf/contrib/drivers/sqlite/v2 v2.3.2
 	github.com/gogf/gf/v2 v2.3.2
 	github.com/iancoleman/orderedmap v0.2.0
 	github.com/samber/lo v1.33.0
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
-	github.com/BurntSushi/toml v1.1.0 // indirect
 	github.com/clbanning/mxj/v2 v2.5.5 // indirect
 	github.com/fatih/color v1.13.0 // indirect
-	github.com/fsnotify/fsnotify v1.5.4 // indirect
 	github.com/glebarez/go-sqlite v1.17.3 // indirect
 	github.com/go-logr/logr v1.2.3 // indirect
 	github.com/go-logr/stdr v1.2.2 // indirect
@@ -32,7 +33,6 @@ require (
 	golang.org/x/net v0.0.0-20220407224826-aac1ed45d8e3 // indirect
 	golang.org/x/sys v0.1.0 // indirect
 	golang.org/x/text v0.3.8-0.20211105212822-18b340fc7af2 // indirect
-	gopkg.in/yaml.v3 v3.0.1 // indirect
 	modernc.org/libc v1.16.8 // indirect
 	modernc.org/mathutil v1.4.1 // indirect
 	modernc.org/memory v1.1.1 // indirect
@@ -0,0 +1,29 @@
+package apijson
+
+import (
+	"context"
+
+	"github.com/glennliao/apijson-go/config"
+	"github.com/glennliao/apijson-go/util"
+)
+
+// Lint 检查配置中的问题, 包括插件提供的配置与实例中注册的执行器; 不需要先 Load
+// dbMeta 为 nil 时使用 DbMetaProvider 加载, 可传入导出的表结构用于 CI
+func (a *ApiJson) Lint(ctx context.Context, dbMeta *config.DBMeta) (problems []config.Problem, err error) {
+	defer util.RecoverErr(&err, "lint")
+
+	a.installPlugins()
+	return a.config.Lint(ctx, config.LintOptions{
+		DbMeta:          dbMeta,
+		QueryExecutors:  a.queryExecutors.List(),
+		ActionExecutors: a.actionRegistry.ExecutorList(),
+	})
+}
+
+// LoadDbMeta 使用 DbMetaProvider (包括插件提供的) 加载表结构, 用于导出表结构
+func (a *ApiJson) LoadDbMeta(ctx context.Context) (dbMeta *config.DBMeta, err error) {
+	defer util.RecoverErr(&err, "load db meta")
+
+	a.installPlugins()
+	return a.config.LoadDbMeta(ctx)
+}
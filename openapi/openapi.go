@@ -0,0 +1,189 @@
+package openapi
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/glennliao/apijson-go"
+	"github.com/glennliao/apijson-go/config"
+	"github.com/glennliao/apijson-go/consts"
+	"github.com/glennliao/apijson-go/model"
+)
+
+// Options 生成文档的选项
+type Options struct {
+	Title   string // 默认 apijson
+	Version string // 默认 1.0.0
+	// 按该角色可访问的表与字段生成, 默认 UNKNOWN
+	Role string
+	// 接口的前缀, 如 /api
+	Prefix string
+	// 响应为 web.SpreadMode, 默认为 web.InDataMode
+	SpreadMode bool
+}
+
+func newGenerator(ctx context.Context, a *apijson.ApiJson, role string) *generator {
+	if role == "" {
+		role = consts.UNKNOWN
+	}
+	return &generator{
+		ctx:       ctx,
+		snapshot:  a.Config().Snapshot(),
+		jsonStyle: a.Config().JsonFieldStyle,
+		role:      role,
+		noVerify:  a.Config().Access.NoVerify,
+	}
+}
+
+// RequestSchemas 各 _request 请求体的 JSON Schema, key 为 tag@method@version; 需在 Load 后调用
+// 字段按 role 可写入的字段限制, 为空时使用 default 的配置
+func RequestSchemas(ctx context.Context, a *apijson.ApiJson, role string) map[string]model.Map {
+	g := newGenerator(ctx, a, role)
+
+	schemas := map[string]model.Map{}
+	for _, request := range g.snapshot.RequestList() {
+		request := request
+		s := g.requestSchema(&request)
+		s["$schema"] = JsonSchemaDialect
+		s["title"] = requestKey(&request)
+		schemas[requestKey(&request)] = s
+	}
+	return schemas
+}
+
+func requestKey(request *config.RequestConfig) string {
+	return request.Tag + "@" + strings.ToUpper(request.Method) + "@" + request.Version
+}
+
+// schemaName components 中的名称, 只能包含字母、数字与 . - _
+func schemaName(parts ...string) string {
+	name := strings.Join(parts, ".")
+	name = strings.ReplaceAll(name, consts.ListKeySuffix, "List")
+	return strings.Map(func(r rune) rune {
+		if r == '.' || r == '-' || r == '_' || ('a' <= r && r <= 'z') || ('A' <= r && r <= 'Z') || ('0' <= r && r <= '9') {
+			return r
+		}
+		return '_'
+	}, name)
+}
+
+func ref(name string) model.Map {
+	return model.Map{"$ref": "#/components/schemas/" + name}
+}
+
+// Document 生成 OpenAPI 3.1 文档, 包含 opts.Role 可访问的表、查询条件与 _request 的请求体; 需在 Load 后调用
+func Document(ctx context.Context, a *apijson.ApiJson, opts Options) model.Map {
+	if opts.Title == "" {
+		opts.Title = "apijson"
+	}
+	if opts.Version == "" {
+		opts.Version = "1.0.0"
+	}
+
+	g := newGenerator(ctx, a, opts.Role)
+	schemas := model.Map{}
+
+	// 查询
+	getProperties := model.Map{}
+	getResult := model.Map{}
+	for _, alias := range g.snapshot.Access().AliasList() {
+		access := g.access(alias)
+		if access == nil || !g.canAccess(access, http.MethodGet) {
+			continue
+		}
+
+		schemas[schemaName(alias)] = g.rowSchema(access)
+		schemas[schemaName(alias, "Query")] = g.querySchema(access, false)
+		schemas[schemaName(alias, "ListQuery")] = g.querySchema(access, true)
+
+		getProperties[alias] = ref(schemaName(alias, "Query"))
+		getProperties[alias+consts.ListKeySuffix] = ref(schemaName(alias, "ListQuery"))
+		getResult[alias] = model.Map{"oneOf": []any{ref(schemaName(alias)), model.Map{"type": "null"}}}
+		getResult[alias+consts.ListKeySuffix] = model.Map{"type": "array", "items": ref(schemaName(alias))}
+	}
+
+	paths := model.Map{
+		opts.Prefix + "/get": operation("get", "查询",
+			model.Map{"type": "object", "properties": getProperties, "additionalProperties": true},
+			model.Map{"type": "object", "properties": getResult, "additionalProperties": true},
+			opts.SpreadMode),
+	}
+
+	// 写入
+	methodRequests := map[string][]any{}
+	for _, request := range g.snapshot.RequestList() {
+		request := request
+		method := strings.ToUpper(request.Method)
+
+		name := strings.TrimSuffix(request.Tag, consts.ListKeySuffix)
+		access := g.access(name)
+		if access == nil || !g.canAccess(access, method) {
+			continue
+		}
+
+		schemaKey := schemaName(request.Tag, method, "v"+request.Version)
+		schemas[schemaKey] = g.requestSchema(&request)
+		methodRequests[method] = append(methodRequests[method], ref(schemaKey))
+	}
+
+	for _, method := range []string{http.MethodPost, http.MethodPut, http.MethodDelete, consts.MethodUpsert} {
+		requests := methodRequests[method]
+		if len(requests) == 0 {
+			continue
+		}
+		path := "/" + strings.ToLower(method)
+		body := model.Map{"oneOf": requests}
+		if len(requests) == 1 {
+			body = requests[0].(model.Map)
+		}
+		paths[opts.Prefix+path] = operation(strings.ToLower(method), strings.ToLower(method), body,
+			model.Map{"type": "object", "additionalProperties": true}, opts.SpreadMode)
+	}
+
+	return model.Map{
+		"openapi":           "3.1.0",
+		"jsonSchemaDialect": JsonSchemaDialect,
+		"info":              model.Map{"title": opts.Title, "version": opts.Version, "description": "role: " + g.role},
+		"paths":             paths,
+		"components":        model.Map{"schemas": schemas},
+	}
+}
+
+// operation apijson 的接口均为 POST, 响应中 ok/code/msg 表示结果
+func operation(operationId string, summary string, body model.Map, data model.Map, spread bool) model.Map {
+	meta := model.Map{
+		"ok":   model.Map{"type": "boolean"},
+		"code": model.Map{"type": "integer"},
+		"msg":  model.Map{"type": "string"},
+		"span": model.Map{"type": "string"},
+	}
+
+	var response model.Map
+	if spread {
+		response = model.Map{"allOf": []any{data, model.Map{"type": "object", "properties": meta}}}
+	} else {
+		properties := model.Map{"data": data}
+		for k, v := range meta {
+			properties[k] = v
+		}
+		response = model.Map{"type": "object", "properties": properties, "required": []string{"ok", "code", "msg"}}
+	}
+
+	return model.Map{
+		"post": model.Map{
+			"operationId": operationId,
+			"summary":     summary,
+			"requestBody": model.Map{
+				"required": true,
+				"content":  model.Map{"application/json": model.Map{"schema": body}},
+			},
+			"responses": model.Map{
+				"200": model.Map{
+					"description": "ok 为 false 时 code 与 msg 为错误信息",
+					"content":     model.Map{"application/json": model.Map{"schema": response}},
+				},
+			},
+		},
+	}
+}
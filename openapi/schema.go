@@ -0,0 +1,352 @@
+package openapi
+
+import (
+	"context"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/glennliao/apijson-go/config"
+	"github.com/glennliao/apijson-go/consts"
+	"github.com/glennliao/apijson-go/model"
+	"github.com/gogf/gf/v2/util/gconv"
+	"github.com/samber/lo"
+)
+
+// JsonSchemaDialect 生成的 JSON Schema 版本, 与 OpenAPI 3.1 一致
+const JsonSchemaDialect = "https://json-schema.org/draft/2020-12/schema"
+
+// generator 按角色从配置快照生成 schema
+type generator struct {
+	ctx       context.Context
+	snapshot  *config.Snapshot
+	jsonStyle config.FieldStyle
+	role      string
+	noVerify  bool
+}
+
+var typeLength = regexp.MustCompile(`^\s*(?:var)?char\s*\(\s*(\d+)\s*\)`)
+
+// columnSchema 字段值的 schema
+func columnSchema(column *config.Column) model.Map {
+	s := model.Map{}
+
+	var typ string
+	switch column.Kind() {
+	case config.KindInt:
+		typ = "integer"
+	case config.KindFloat:
+		typ = "number"
+	case config.KindDecimal:
+		// 可传入字符串避免精度丢失
+		s["type"] = []string{"number", "string"}
+	case config.KindBool:
+		typ = "boolean"
+	case config.KindTime:
+		typ = "string"
+		switch strings.ToLower(strings.TrimSpace(column.Type)) {
+		case "date":
+			s["format"] = "date"
+		case "time":
+			s["format"] = "time"
+		default:
+			s["format"] = "date-time"
+		}
+	case config.KindBytes:
+		typ = "string"
+		s["contentEncoding"] = "base64"
+	case config.KindString:
+		typ = "string"
+		if match := typeLength.FindStringSubmatch(strings.ToLower(column.Type)); match != nil {
+			s["maxLength"] = gconv.Int(match[1])
+		}
+	}
+
+	if typ != "" {
+		if column.Nullable {
+			s["type"] = []string{typ, "null"}
+		} else {
+			s["type"] = typ
+		}
+	}
+
+	if column.Comment != "" {
+		s["description"] = column.Comment
+	}
+
+	return s
+}
+
+func (g *generator) access(alias string) *config.AccessConfig {
+	access, err := g.snapshot.Access().GetAccess(alias, false)
+	if err != nil {
+		return nil
+	}
+	return access
+}
+
+func (g *generator) table(access *config.AccessConfig) *config.Table {
+	return g.snapshot.DbMeta().GetTable(access.Name)
+}
+
+func (g *generator) field(access *config.AccessConfig, column string) string {
+	return g.jsonStyle(g.ctx, access.Name, column)
+}
+
+// canAccess 角色是否有该请求方式的权限
+func (g *generator) canAccess(access *config.AccessConfig, method string) bool {
+	if g.noVerify {
+		return true
+	}
+	var roles []string
+	switch method {
+	case http.MethodGet:
+		roles = access.Get
+	case http.MethodPost:
+		roles = access.Post
+	case http.MethodPut:
+		roles = access.Put
+	case http.MethodDelete:
+		roles = access.Delete
+	case consts.MethodUpsert:
+		roles = lo.Intersect(access.Post, access.Put)
+	}
+	return access.HasRole(roles, g.role)
+}
+
+// rowSchema 角色可读取的行, 为 FieldsGet 中的 Out, 未配置时为全部字段
+func (g *generator) rowSchema(access *config.AccessConfig) model.Map {
+	var out []string
+	if fieldsGet := access.GetFieldsGetByRole(g.role); fieldsGet != nil && !g.noVerify {
+		out = lo.Keys(fieldsGet.Out)
+	}
+
+	properties := model.Map{}
+	s := model.Map{"type": "object", "properties": properties}
+
+	table := g.table(access)
+	if table == nil {
+		for _, column := range out {
+			properties[g.field(access, column)] = model.Map{}
+		}
+		if len(out) == 0 {
+			s["additionalProperties"] = true
+		}
+		return s
+	}
+
+	if table.Comment != "" {
+		s["description"] = table.Comment
+	}
+	for i := range table.Columns {
+		column := &table.Columns[i]
+		if len(out) == 0 || lo.Contains(out, column.Name) {
+			properties[g.field(access, column.Name)] = columnSchema(column)
+		}
+	}
+	return s
+}
+
+// querySchema 角色可使用的查询条件, 为 FieldsGet 中的 In
+func (g *generator) querySchema(access *config.AccessConfig, isList bool) model.Map {
+	properties := model.Map{
+		consts.Column: model.Map{"type": "string", "description": "返回的字段, 如 id,name:alias"},
+		"@order":      model.Map{"type": "string", "description": "排序, 如 id-,name+"},
+		"@group":      model.Map{"type": "string"},
+		consts.Role:   model.Map{"type": "string"},
+	}
+
+	if isList {
+		maxCount := 0
+		if fieldsGet := access.GetFieldsGetByRole(g.role); fieldsGet != nil && fieldsGet.MaxCount != nil {
+			maxCount = *fieldsGet.MaxCount
+		}
+		count := model.Map{"type": "integer", "minimum": 0}
+		if maxCount > 0 {
+			count["maximum"] = maxCount
+		}
+		properties[consts.Page] = model.Map{"type": "integer", "minimum": 0}
+		properties[consts.Count] = count
+		properties[consts.Query] = model.Map{"type": "integer", "enum": []int{0, 1, 2}, "description": "0 数据, 1 总数, 2 数据与总数"}
+	}
+
+	table := g.table(access)
+	columnOf := func(column string) model.Map {
+		if table != nil {
+			if c := table.Column(column); c != nil {
+				s := columnSchema(c)
+				delete(s, "description")
+				return s
+			}
+		}
+		return model.Map{}
+	}
+
+	in := map[string][]string{}
+	if g.noVerify {
+		if table != nil {
+			for _, column := range table.Columns {
+				in[column.Name] = []string{"*"}
+			}
+		}
+	} else if fieldsGet := access.GetFieldsGetByRole(g.role); fieldsGet != nil {
+		in = fieldsGet.In
+	}
+
+	for column, ops := range in {
+		field := g.field(access, column)
+		all := lo.Contains(ops, "*")
+		if all || len(ops) == 0 || lo.Contains(ops, consts.SqlEqual) {
+			properties[field] = columnOf(column)
+		}
+		if all || lo.Contains(ops, "in") || lo.Contains(ops, consts.OpIn) {
+			properties[field+consts.OpIn] = model.Map{
+				"oneOf": []any{
+					model.Map{"type": "array", "items": columnOf(column)},
+					model.Map{"type": "string", "description": "条件, 如 >=1,<10"},
+				},
+			}
+		}
+		if all || lo.ContainsBy(ops, func(op string) bool { return strings.Contains(op, consts.OpLike) }) {
+			properties[field+consts.OpLike] = model.Map{"type": "string", "description": "模糊搜索, 如 %name%"}
+		}
+		if all || lo.Contains(ops, consts.SqlRegexp) || lo.Contains(ops, consts.OpRegexp) {
+			properties[field+consts.OpRegexp] = model.Map{"type": "string", "description": "正则搜索"}
+		}
+	}
+
+	s := model.Map{"type": "object", "properties": properties}
+	if table != nil {
+		// 引用 key@ 与函数 key() 不在其中
+		s["patternProperties"] = model.Map{`@$`: model.Map{"type": "string"}, `\(\)$`: model.Map{"type": "string"}}
+		s["additionalProperties"] = false
+	}
+	return s
+}
+
+// writeSchema 请求中一个节点的 schema, 字段为表中的字段去除 REFUSE、自动填充与租户字段, 并按角色可写入的字段限制
+func (g *generator) writeSchema(access *config.AccessConfig, method string, structure *config.Structure) model.Map {
+	properties := model.Map{consts.Role: model.Map{"type": "string"}}
+	s := model.Map{"type": "object", "properties": properties}
+
+	var required []string
+	for _, key := range structure.Must {
+		required = append(required, key)
+		properties[key] = model.Map{}
+	}
+
+	table := g.table(access)
+	if table == nil {
+		s["additionalProperties"] = true
+		if len(required) > 0 {
+			s["required"] = required
+		}
+		return s
+	}
+
+	refuseAll := len(structure.Refuse) > 0 && structure.Refuse[0] == "!"
+	fields, limited := access.GetFieldsWriteByRole(method, g.role)
+	if g.noVerify {
+		limited = false
+	}
+	rowKeys := access.RowKeys()
+
+	for i := range table.Columns {
+		column := &table.Columns[i]
+		field := g.field(access, column.Name)
+
+		if _, exists := access.AutoFill[column.Name]; exists || column.Name == access.TenantColumn {
+			continue
+		}
+		if lo.Contains(structure.Refuse, field) || (refuseAll && !lo.Contains(structure.Must, field)) {
+			continue
+		}
+		// UPDATE 中的字段由服务端设置
+		if _, exists := structure.Update[field]; exists {
+			continue
+		}
+		if _, exists := structure.Update[field+consts.FunctionsKeySuffix]; exists {
+			continue
+		}
+
+		isRowKey := lo.Contains(rowKeys, column.Name)
+		switch method {
+		case http.MethodDelete:
+			if !isRowKey {
+				continue
+			}
+		case http.MethodPut:
+			if limited && !isRowKey && column.Name != access.VersionColumn && !lo.Contains(fields, column.Name) {
+				continue
+			}
+		default:
+			if limited && !lo.Contains(fields, column.Name) {
+				continue
+			}
+		}
+
+		properties[field] = columnSchema(column)
+		if (method == http.MethodPut || method == http.MethodDelete) && isRowKey && len(rowKeys) == 1 {
+			properties[field+consts.OpIn] = model.Map{"type": "array", "items": columnSchema(column), "minItems": 1}
+		}
+	}
+
+	if method == http.MethodPost {
+		properties[consts.Return] = model.Map{"type": "string", "description": "写入后回查的字段"}
+	}
+
+	// 引用 key@
+	s["patternProperties"] = model.Map{`@$`: model.Map{"type": "string"}}
+	s["additionalProperties"] = false
+	if len(required) > 0 {
+		s["required"] = required
+	}
+	return s
+}
+
+// requestSchema _request 中一个 tag/version 的请求体
+func (g *generator) requestSchema(request *config.RequestConfig) model.Map {
+	properties := model.Map{
+		consts.Tag:     model.Map{"const": request.Tag},
+		consts.Version: model.Map{"type": []string{"string", "integer"}},
+	}
+	required := []string{consts.Tag}
+
+	for _, key := range sortedKeys(request.Structure) {
+		name := strings.TrimSuffix(key, consts.ListKeySuffix)
+		access := g.access(name)
+		if access == nil {
+			continue
+		}
+
+		structure := request.Structure[key]
+		if structure == nil {
+			structure = &config.Structure{}
+		}
+
+		node := g.writeSchema(access, strings.ToUpper(request.Method), structure)
+		if strings.HasSuffix(key, consts.ListKeySuffix) {
+			node = model.Map{"type": "array", "items": node, "minItems": 1}
+		}
+		properties[key] = node
+		required = append(required, key)
+	}
+
+	s := model.Map{
+		"type":                 "object",
+		"properties":           properties,
+		"required":             required,
+		"additionalProperties": false,
+	}
+	if request.Detail != "" {
+		s["description"] = request.Detail
+	}
+	return s
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := lo.Keys(m)
+	sort.Strings(keys)
+	return keys
+}
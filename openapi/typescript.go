@@ -0,0 +1,260 @@
+package openapi
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/glennliao/apijson-go"
+	"github.com/glennliao/apijson-go/consts"
+	"github.com/glennliao/apijson-go/model"
+	"github.com/samber/lo"
+)
+
+var tsIdentifier = regexp.MustCompile(`^[A-Za-z_$][A-Za-z0-9_$]*$`)
+
+// 对应 patternProperties 的 key 类型
+var tsPatternKeys = map[string]string{
+	`@$`:    "`${string}@`",
+	`\(\)$`: "`${string}()`",
+}
+
+// TypeScript 生成 TypeScript 声明, 每个角色一个 namespace, 包含表的行 (FieldsGet 中的 Out)、查询条件、
+// _request 各 tag/version 的请求体 (MUST 为必填) 与 /get、/post、/put、/delete、/upsert 的请求函数; 需在 Load 后调用
+// roles 为空时为 Access.RoleList() 中的全部角色
+func TypeScript(ctx context.Context, a *apijson.ApiJson, opts Options, roles []string) string {
+	if len(roles) == 0 {
+		roles = a.Config().Access.RoleList()
+	}
+
+	w := &tsWriter{}
+	w.line("// Code generated by apijson. DO NOT EDIT.")
+	w.line("")
+	if opts.SpreadMode {
+		w.line("export type Response<T> = T & { ok: boolean; code: number; msg: string; span?: string };")
+	} else {
+		w.line("export type Response<T> = { ok: boolean; code: number; msg: string; span?: string; data: T };")
+	}
+	w.line("")
+	w.line("export type Fetcher = <T>(path: string, body: unknown) => Promise<T>;")
+	w.line("")
+	w.line("export const fetchJson = (baseUrl = \"\", init: RequestInit = {}): Fetcher =>")
+	w.line("  <T>(path: string, body: unknown) =>")
+	w.line("    fetch(baseUrl + path, { ...init, method: \"POST\", headers: { \"Content-Type\": \"application/json\", ...init.headers }, body: JSON.stringify(body) })")
+	w.line("      .then((res) => res.json() as Promise<T>);")
+
+	for _, role := range roles {
+		w.line("")
+		g := newGenerator(ctx, a, role)
+		g.writeTypeScript(w, opts.Prefix)
+	}
+
+	return w.String()
+}
+
+func (g *generator) writeTypeScript(w *tsWriter, prefix string) {
+	w.line("export namespace %s {", tsName(g.role))
+	w.indent++
+
+	var getReq, getRes []string
+	for _, alias := range g.snapshot.Access().AliasList() {
+		access := g.access(alias)
+		if access == nil || !g.canAccess(access, http.MethodGet) {
+			continue
+		}
+		name := tsName(alias)
+
+		w.line("export interface %s %s", name, tsType(g.rowSchema(access), true, w.indent))
+		w.line("export interface %sQuery %s", name, tsType(g.querySchema(access, false), false, w.indent))
+		w.line("export interface %sListQuery %s", name, tsType(g.querySchema(access, true), false, w.indent))
+
+		getReq = append(getReq, tsKey(alias)+"?: "+name+"Query", tsKey(alias+consts.ListKeySuffix)+"?: "+name+"ListQuery")
+		getRes = append(getRes, tsKey(alias)+": "+name+" | null", tsKey(alias+consts.ListKeySuffix)+": "+name+"[]")
+	}
+
+	// 可再使用 "[]"、引用等, 不在其中的 key 为 unknown
+	w.line("export interface GetReq {")
+	for _, field := range getReq {
+		w.line("  %s;", field)
+	}
+	w.line("  [key: string]: unknown;")
+	w.line("}")
+	w.line("export interface GetRes {")
+	for _, field := range getRes {
+		w.line("  %s;", field)
+	}
+	w.line("  [key: string]: unknown;")
+	w.line("}")
+
+	methodRequests := map[string][]string{}
+	for _, request := range g.snapshot.RequestList() {
+		request := request
+		method := strings.ToUpper(request.Method)
+
+		access := g.access(strings.TrimSuffix(request.Tag, consts.ListKeySuffix))
+		if access == nil || !g.canAccess(access, method) {
+			continue
+		}
+
+		name := tsName(schemaName(request.Tag, method, "v"+request.Version))
+		if request.Detail != "" {
+			w.line("%s", tsComment(request.Detail))
+		}
+		w.line("export interface %s %s", name, tsType(g.requestSchema(&request), false, w.indent))
+		methodRequests[method] = append(methodRequests[method], name)
+	}
+
+	methods := []string{http.MethodPost, http.MethodPut, http.MethodDelete, consts.MethodUpsert}
+	for _, method := range methods {
+		union := "never"
+		if len(methodRequests[method]) > 0 {
+			union = strings.Join(methodRequests[method], " | ")
+		}
+		w.line("export type %sReq = %s;", tsMethod(method), union)
+	}
+
+	w.line("export const createClient = (fetcher: Fetcher) => ({")
+	w.line("  get: <R extends GetReq>(req: R) =>")
+	w.line("    fetcher<Response<Pick<GetRes, keyof R & keyof GetRes>>>(%q, req),", prefix+"/get")
+	for _, method := range methods {
+		if len(methodRequests[method]) == 0 {
+			continue
+		}
+		w.line("  %s: (req: %sReq) => fetcher<Response<Record<string, unknown>>>(%q, req),",
+			strings.ToLower(method), tsMethod(method), prefix+"/"+strings.ToLower(method))
+	}
+	w.line("});")
+
+	w.indent--
+	w.line("}")
+}
+
+type tsWriter struct {
+	strings.Builder
+	indent int
+}
+
+func (w *tsWriter) line(format string, args ...any) {
+	if format != "" {
+		w.WriteString(strings.Repeat("  ", w.indent))
+	}
+	w.WriteString(fmt.Sprintf(format, args...))
+	w.WriteString("\n")
+}
+
+func tsComment(text string) string {
+	return "/** " + strings.ReplaceAll(text, "*/", "*\\/") + " */"
+}
+
+func tsMethod(method string) string {
+	method = strings.ToLower(method)
+	return strings.ToUpper(method[:1]) + method[1:]
+}
+
+// tsName 类型名, 只能包含字母、数字与 _ $
+func tsName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		if r == '_' || r == '$' || ('a' <= r && r <= 'z') || ('A' <= r && r <= 'Z') || ('0' <= r && r <= '9') {
+			return r
+		}
+		return '_'
+	}, name)
+	if name == "" || ('0' <= name[0] && name[0] <= '9') {
+		name = "_" + name
+	}
+	return name
+}
+
+func tsKey(key string) string {
+	if tsIdentifier.MatchString(key) {
+		return key
+	}
+	return fmt.Sprintf("%q", key)
+}
+
+// tsType 将 schema 转换为 TypeScript 类型, allRequired 时所有属性均为必填
+func tsType(s model.Map, allRequired bool, indent int) string {
+	if ref, ok := s["$ref"].(string); ok {
+		return tsName(ref[strings.LastIndex(ref, "/")+1:])
+	}
+	if c, ok := s["const"]; ok {
+		return fmt.Sprintf("%q", fmt.Sprint(c))
+	}
+	if enum, ok := s["enum"].([]int); ok {
+		return strings.Join(lo.Map(enum, func(v int, _ int) string { return fmt.Sprint(v) }), " | ")
+	}
+	for _, key := range []string{"oneOf", "allOf"} {
+		if list, ok := s[key].([]any); ok {
+			sep := " | "
+			if key == "allOf" {
+				sep = " & "
+			}
+			return strings.Join(lo.Map(list, func(item any, _ int) string { return tsType(item.(model.Map), false, indent) }), sep)
+		}
+	}
+
+	var types []string
+	switch typ := s["type"].(type) {
+	case string:
+		types = []string{typ}
+	case []string:
+		types = typ
+	default:
+		return "unknown"
+	}
+
+	return strings.Join(lo.Map(types, func(typ string, _ int) string {
+		switch typ {
+		case "integer", "number":
+			return "number"
+		case "string", "boolean", "null":
+			return typ
+		case "array":
+			items, _ := s["items"].(model.Map)
+			itemType := tsType(items, allRequired, indent)
+			if strings.ContainsAny(itemType, "|&") {
+				itemType = "(" + itemType + ")"
+			}
+			return itemType + "[]"
+		case "object":
+			return tsObject(s, allRequired, indent)
+		}
+		return "unknown"
+	}), " | ")
+}
+
+func tsObject(s model.Map, allRequired bool, indent int) string {
+	properties, _ := s["properties"].(model.Map)
+	required, _ := s["required"].([]string)
+	pad := strings.Repeat("  ", indent+1)
+
+	var b strings.Builder
+	b.WriteString("{\n")
+	for _, key := range sortedKeys(properties) {
+		property := properties[key].(model.Map)
+		if description, ok := property["description"].(string); ok {
+			b.WriteString(pad + tsComment(description) + "\n")
+		}
+		optional := "?"
+		if allRequired || lo.Contains(required, key) {
+			optional = ""
+		}
+		b.WriteString(fmt.Sprintf("%s%s%s: %s;\n", pad, tsKey(key), optional, tsType(property, false, indent+1)))
+	}
+
+	if patterns, ok := s["patternProperties"].(model.Map); ok {
+		for _, pattern := range sortedKeys(patterns) {
+			if keyType, ok := tsPatternKeys[pattern]; ok {
+				b.WriteString(fmt.Sprintf("%s[key: %s]: %s;\n", pad, keyType, tsType(patterns[pattern].(model.Map), false, indent+1)))
+			}
+		}
+	}
+	if additional, ok := s["additionalProperties"].(bool); ok && additional {
+		b.WriteString(pad + "[key: string]: unknown;\n")
+	}
+
+	b.WriteString(strings.Repeat("  ", indent) + "}")
+	return b.String()
+}
@@ -0,0 +1,210 @@
+package apijson
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/glennliao/apijson-go/action"
+	"github.com/glennliao/apijson-go/config"
+	"github.com/glennliao/apijson-go/query"
+	"github.com/samber/lo"
+)
+
+// Plugin 插件, 通过 UsePlugin 注册, Load 时按依赖顺序 Install
+// 可选实现 PluginName、PluginDepends、PluginStarter、PluginStopper
+type Plugin interface {
+	Install(ctx context.Context, a *ApiJson)
+}
+
+// PluginName 插件名, 用于依赖声明, 未实现时为类型名
+type PluginName interface {
+	Name() string
+}
+
+// PluginDepends 插件依赖的其他插件名, 依赖的插件先 Install/Start, 后 Stop
+type PluginDepends interface {
+	Depends() []string
+}
+
+// PluginStarter Start 时调用, 如启动后台任务
+type PluginStarter interface {
+	Start(ctx context.Context) error
+}
+
+// PluginStopper Stop 时调用, 如关闭连接
+type PluginStopper interface {
+	Stop(ctx context.Context) error
+}
+
+// Contributions 插件提供的函数、执行器、hook、主键生成、配置 provider, 在 Install 中通过 Contribute 注册到实例
+type Contributions struct {
+	Functions            map[string]config.Func
+	QueryExecutors       map[string]query.ExecutorBuilder
+	ActionExecutors      map[string]action.ActionExecutor
+	Hooks                []action.Hook
+	TransactionResolver  action.TransactionResolver
+	AuditSink            action.AuditSink
+	RowKeyGens           map[string]config.RowKeyGenFuncHandler
+	AccessListProviders  map[string]config.AccessListProvider
+	RequestListProviders map[string]config.RequestListProvider
+	DbMetaProviders      map[string]config.DbMetaProvider
+}
+
+func pluginName(p Plugin) string {
+	if named, ok := p.(PluginName); ok {
+		return named.Name()
+	}
+	t := reflect.TypeOf(p)
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t.String()
+}
+
+// UsePlugin 注册插件, Load 时 Install
+func (a *ApiJson) UsePlugin(p ...Plugin) *ApiJson {
+	a.plugins = append(a.plugins, p...)
+	return a
+}
+
+// Contribute 将插件提供的内容注册到实例
+func (a *ApiJson) Contribute(c *Contributions) {
+	for name, f := range c.Functions {
+		a.config.Functions.Bind(name, f)
+	}
+	for name, builder := range c.QueryExecutors {
+		a.queryExecutors.RegExecutor(name, builder)
+	}
+	for name, executor := range c.ActionExecutors {
+		a.actionRegistry.RegExecutor(name, executor)
+	}
+	for _, hook := range c.Hooks {
+		a.actionRegistry.RegHook(hook)
+	}
+	if c.TransactionResolver != nil {
+		a.actionRegistry.RegTransactionResolver(c.TransactionResolver)
+	}
+	if c.AuditSink != nil {
+		a.actionRegistry.RegAuditSink(c.AuditSink)
+	}
+	for name, gen := range c.RowKeyGens {
+		a.config.RowKeyGenFunc(name, gen)
+	}
+	for name, provider := range c.AccessListProviders {
+		a.config.RegAccessListProvider(name, provider)
+	}
+	for name, provider := range c.RequestListProviders {
+		a.config.RegRequestListProvider(name, provider)
+	}
+	for name, provider := range c.DbMetaProviders {
+		a.config.RegDbMetaProvider(name, provider)
+	}
+}
+
+// sortPlugins 按依赖排序, 无依赖关系的保持注册顺序; 依赖不存在、有环或重名时返回错误
+func sortPlugins(plugins []Plugin) ([]Plugin, error) {
+	byName := map[string]Plugin{}
+	for _, p := range plugins {
+		name := pluginName(p)
+		if _, exists := byName[name]; exists {
+			return nil, fmt.Errorf("plugin %s: duplicate", name)
+		}
+		byName[name] = p
+	}
+
+	const (
+		visiting = 1
+		visited  = 2
+	)
+	state := map[string]int{}
+	var sorted []Plugin
+
+	var visit func(name string, path []string) error
+	visit = func(name string, path []string) error {
+		switch state[name] {
+		case visiting:
+			return fmt.Errorf("plugin depends cycle: %s", strings.Join(append(path, name), " -> "))
+		case visited:
+			return nil
+		}
+
+		p := byName[name]
+		state[name] = visiting
+		if depends, ok := p.(PluginDepends); ok {
+			for _, depend := range depends.Depends() {
+				if _, exists := byName[depend]; !exists {
+					return fmt.Errorf("plugin %s: depends %s not found", name, depend)
+				}
+				if err := visit(depend, append(path, name)); err != nil {
+					return err
+				}
+			}
+		}
+		state[name] = visited
+		sorted = append(sorted, p)
+		return nil
+	}
+
+	for _, p := range plugins {
+		if err := visit(pluginName(p), nil); err != nil {
+			return nil, err
+		}
+	}
+	return sorted, nil
+}
+
+// installPlugins 按依赖顺序 Install 未安装的插件, 出错时 panic
+func (a *ApiJson) installPlugins() {
+	sorted, err := sortPlugins(a.plugins)
+	if err != nil {
+		panic(err)
+	}
+
+	for _, p := range sorted {
+		name := pluginName(p)
+		if lo.ContainsBy(a.installed, func(item Plugin) bool { return pluginName(item) == name }) {
+			continue
+		}
+		p.Install(a.ctx, a)
+		a.installed = append(a.installed, p)
+	}
+}
+
+// Start 按依赖顺序启动插件, 需在 Load 后调用; 出错时停止已启动的插件并返回错误
+func (a *ApiJson) Start(ctx context.Context) error {
+	for _, p := range a.installed[len(a.started):] {
+		if starter, ok := p.(PluginStarter); ok {
+			if err := starter.Start(ctx); err != nil {
+				err = fmt.Errorf("plugin %s start: %w", pluginName(p), err)
+				if stopErr := a.Stop(ctx); stopErr != nil {
+					err = fmt.Errorf("%w; %s", err, stopErr)
+				}
+				return err
+			}
+		}
+		a.started = append(a.started, p)
+	}
+	return nil
+}
+
+// Stop 按启动的相反顺序停止插件, 返回所有的错误
+func (a *ApiJson) Stop(ctx context.Context) error {
+	var errs []error
+	for i := len(a.started) - 1; i >= 0; i-- {
+		p := a.started[i]
+		if stopper, ok := p.(PluginStopper); ok {
+			if err := stopper.Stop(ctx); err != nil {
+				errs = append(errs, fmt.Errorf("plugin %s stop: %w", pluginName(p), err))
+			}
+		}
+	}
+	a.started = nil
+
+	if len(errs) == 0 {
+		return nil
+	}
+	msg := lo.Map(errs, func(err error, _ int) string { return err.Error() })
+	return fmt.Errorf("%w%s", errs[0], strings.Join(append([]string{""}, msg[1:]...), "; "))
+}
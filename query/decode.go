@@ -0,0 +1,468 @@
+package query
+
+import (
+	"database/sql"
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/glennliao/apijson-go/consts"
+	"github.com/glennliao/apijson-go/model"
+	"github.com/gogf/gf/v2/os/gtime"
+)
+
+// DecodeError 结果中的节点无法解码到结构体, Path 为节点在结果中的路径, 如 []/0/Todo[]/1/createdAt
+type DecodeError struct {
+	Path string
+	Err  error
+}
+
+func (e *DecodeError) Error() string {
+	return fmt.Sprintf("decode %s: %s", e.Path, e.Err)
+}
+
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
+
+// ResultInto 查询并将结果解码到 dest (结构体指针), 见 Decode
+func (q *Query) ResultInto(dest any) error {
+	ret, err := q.Result()
+	if err != nil {
+		return err
+	}
+	return Decode(ret, dest)
+}
+
+// ResultAs 查询并将结果解码为 T, 如
+//
+//	type Result struct {
+//		User  *User
+//		Todos []Todo `apijson:"Todo[]"`
+//		List  []struct {
+//			User  User
+//			Todos []Todo `apijson:"Todo[]"`
+//		} `apijson:"[]"`
+//		Total int `apijson:"total"`
+//	}
+//	ret, err := query.ResultAs[Result](a.NewQuery(ctx, req))
+func ResultAs[T any](q *Query) (T, error) {
+	var ret T
+	err := q.ResultInto(&ret)
+	return ret, err
+}
+
+// Decode 将查询结果 (或 client 返回的 data) 解码到 dest (结构体指针)
+// 字段对应的 key 依次为 apijson tag、json tag、字段名 (不区分大小写, 切片时可省略 [] 后缀); 结果中不存在的 key 保持零值
+// 值按字段类型严格转换, 如字符串字段接收数字, 整数字段不接收 "1a"; 实现 sql.Scanner 的字段使用 Scan, JSON 字段可为字符串
+func Decode(ret model.Map, dest any) error {
+	rv := reflect.ValueOf(dest)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return consts.NewSysErr(fmt.Sprintf("decode: dest must be a non-nil pointer, got %T", dest))
+	}
+	return decodeValue("", map[string]any(ret), rv.Elem())
+}
+
+var (
+	timeType    = reflect.TypeOf(time.Time{})
+	gtimeType   = reflect.TypeOf(gtime.Time{})
+	scannerType = reflect.TypeOf((*sql.Scanner)(nil)).Elem()
+	textType    = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+func decodeErr(path string, err error) error {
+	if _, ok := err.(*DecodeError); ok {
+		return err
+	}
+	if path == "" {
+		path = "root"
+	}
+	return &DecodeError{Path: path, Err: err}
+}
+
+func joinPath(path string, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "/" + key
+}
+
+func decodeValue(path string, val any, dst reflect.Value) error {
+	if val == nil {
+		return nil
+	}
+	if rv := reflect.ValueOf(val); rv.Kind() == reflect.Pointer && rv.IsNil() {
+		return nil
+	}
+
+	if dst.Kind() == reflect.Pointer {
+		if dst.IsNil() {
+			dst.Set(reflect.New(dst.Type().Elem()))
+		}
+		return decodeValue(path, val, dst.Elem())
+	}
+
+	if dst.CanAddr() && dst.Addr().Type().Implements(scannerType) {
+		if err := dst.Addr().Interface().(sql.Scanner).Scan(val); err != nil {
+			return decodeErr(path, err)
+		}
+		return nil
+	}
+
+	if dst.Kind() == reflect.Interface {
+		if dst.NumMethod() == 0 {
+			dst.Set(reflect.ValueOf(val))
+			return nil
+		}
+		if rv := reflect.ValueOf(val); rv.Type().AssignableTo(dst.Type()) {
+			dst.Set(rv)
+			return nil
+		}
+		return decodeErr(path, fmt.Errorf("cannot decode %T into %s", val, dst.Type()))
+	}
+
+	switch dst.Type() {
+	case timeType, gtimeType:
+		t, err := toTime(val)
+		if err != nil {
+			return decodeErr(path, err)
+		}
+		if dst.Type() == timeType {
+			dst.Set(reflect.ValueOf(t))
+		} else {
+			dst.Set(reflect.ValueOf(*gtime.New(t)))
+		}
+		return nil
+	}
+
+	switch dst.Kind() {
+	case reflect.Struct:
+		if m, ok := toMap(val); ok {
+			return decodeStruct(path, m, dst)
+		}
+		return decodeJson(path, val, dst)
+
+	case reflect.Map:
+		m, ok := toMap(val)
+		if !ok {
+			return decodeJson(path, val, dst)
+		}
+		if dst.Type().Key().Kind() != reflect.String {
+			return decodeErr(path, fmt.Errorf("map key must be string, got %s", dst.Type()))
+		}
+		if dst.IsNil() {
+			dst.Set(reflect.MakeMapWithSize(dst.Type(), len(m)))
+		}
+		for k, v := range m {
+			item := reflect.New(dst.Type().Elem()).Elem()
+			if err := decodeValue(joinPath(path, k), v, item); err != nil {
+				return err
+			}
+			dst.SetMapIndex(reflect.ValueOf(k).Convert(dst.Type().Key()), item)
+		}
+		return nil
+
+	case reflect.Slice, reflect.Array:
+		if dst.Kind() == reflect.Slice && dst.Type().Elem().Kind() == reflect.Uint8 {
+			switch v := val.(type) {
+			case []byte:
+				dst.SetBytes(append([]byte(nil), v...))
+				return nil
+			case string:
+				dst.SetBytes([]byte(v))
+				return nil
+			}
+		}
+
+		list := reflect.ValueOf(val)
+		if list.Kind() != reflect.Slice && list.Kind() != reflect.Array {
+			return decodeJson(path, val, dst)
+		}
+		if dst.Kind() == reflect.Slice {
+			dst.Set(reflect.MakeSlice(dst.Type(), list.Len(), list.Len()))
+		} else if list.Len() > dst.Len() {
+			return decodeErr(path, fmt.Errorf("%d items into %s", list.Len(), dst.Type()))
+		}
+		for i := 0; i < list.Len(); i++ {
+			if err := decodeValue(joinPath(path, strconv.Itoa(i)), list.Index(i).Interface(), dst.Index(i)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if err := decodeScalar(val, dst); err != nil {
+		return decodeErr(path, err)
+	}
+	return nil
+}
+
+func toMap(val any) (map[string]any, bool) {
+	switch v := val.(type) {
+	case model.Map:
+		return v, true
+	case map[string]any:
+		return v, true
+	}
+	return nil, false
+}
+
+// decodeJson JSON 字段中的对象、数组
+func decodeJson(path string, val any, dst reflect.Value) error {
+	var content []byte
+	switch v := val.(type) {
+	case string:
+		content = []byte(v)
+	case []byte:
+		content = v
+	default:
+		return decodeErr(path, fmt.Errorf("cannot decode %T into %s", val, dst.Type()))
+	}
+
+	ptr := reflect.New(dst.Type())
+	if err := json.Unmarshal(content, ptr.Interface()); err != nil {
+		return decodeErr(path, err)
+	}
+	dst.Set(ptr.Elem())
+	return nil
+}
+
+// fieldKey 结构体字段对应的 key, 为空时跳过
+func fieldKey(field reflect.StructField) string {
+	for _, tag := range []string{"apijson", "json"} {
+		if name, ok := field.Tag.Lookup(tag); ok {
+			name, _, _ = strings.Cut(name, ",")
+			if name == "-" {
+				return ""
+			}
+			if name != "" {
+				return name
+			}
+		}
+	}
+	return field.Name
+}
+
+func lookup(m map[string]any, key string, isList bool) (string, any, bool) {
+	if v, exists := m[key]; exists {
+		return key, v, true
+	}
+	for k, v := range m {
+		if strings.EqualFold(k, key) || (isList && strings.EqualFold(k, key+consts.ListKeySuffix)) {
+			return k, v, true
+		}
+	}
+	return "", nil, false
+}
+
+func decodeStruct(path string, m map[string]any, dst reflect.Value) error {
+	typ := dst.Type()
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+
+		// 未设置 tag 的嵌入结构体, 字段在同一层
+		if field.Anonymous && field.Tag.Get("apijson") == "" && field.Tag.Get("json") == "" {
+			ft := field.Type
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct && ft != timeType && ft != gtimeType {
+				fv := dst.Field(i)
+				if fv.Kind() == reflect.Pointer {
+					if !field.IsExported() {
+						continue
+					}
+					if fv.IsNil() {
+						fv.Set(reflect.New(ft))
+					}
+					fv = fv.Elem()
+				}
+				if err := decodeStruct(path, m, fv); err != nil {
+					return err
+				}
+				continue
+			}
+		}
+
+		if !field.IsExported() {
+			continue
+		}
+		key := fieldKey(field)
+		if key == "" {
+			continue
+		}
+
+		k, v, exists := lookup(m, key, field.Type.Kind() == reflect.Slice)
+		if !exists {
+			continue
+		}
+		if err := decodeValue(joinPath(path, k), v, dst.Field(i)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func toTime(val any) (time.Time, error) {
+	switch v := val.(type) {
+	case time.Time:
+		return v, nil
+	case *time.Time:
+		return *v, nil
+	case gtime.Time:
+		return v.Time, nil
+	case *gtime.Time:
+		return v.Time, nil
+	case string:
+		t, err := gtime.StrToTime(v)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid time %q", v)
+		}
+		return t.Time, nil
+	case []byte:
+		return toTime(string(v))
+	}
+	return time.Time{}, fmt.Errorf("cannot decode %T into time", val)
+}
+
+func decodeScalar(val any, dst reflect.Value) error {
+	if dst.CanAddr() && dst.Addr().Type().Implements(textType) {
+		if s, ok := val.(string); ok {
+			return dst.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(s))
+		}
+	}
+
+	rv := reflect.ValueOf(val)
+	if b, ok := val.([]byte); ok {
+		rv = reflect.ValueOf(string(b))
+	}
+
+	switch dst.Kind() {
+	case reflect.String:
+		switch rv.Kind() {
+		case reflect.String:
+			dst.SetString(rv.String())
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Bool:
+			dst.SetString(fmt.Sprint(val))
+		case reflect.Float32, reflect.Float64:
+			dst.SetString(strconv.FormatFloat(rv.Float(), 'f', -1, 64))
+		default:
+			if s, ok := val.(fmt.Stringer); ok {
+				dst.SetString(s.String())
+				return nil
+			}
+			return fmt.Errorf("cannot decode %T into %s", val, dst.Type())
+		}
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		var n int64
+		switch rv.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			n = rv.Int()
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			if rv.Uint() > math.MaxInt64 {
+				return fmt.Errorf("%v overflows %s", val, dst.Type())
+			}
+			n = int64(rv.Uint())
+		case reflect.Float32, reflect.Float64:
+			if rv.Float() != math.Trunc(rv.Float()) {
+				return fmt.Errorf("%v is not an integer", val)
+			}
+			n = int64(rv.Float())
+		case reflect.Bool:
+			if rv.Bool() {
+				n = 1
+			}
+		case reflect.String:
+			var err error
+			if n, err = strconv.ParseInt(strings.TrimSpace(rv.String()), 10, 64); err != nil {
+				return fmt.Errorf("invalid integer %q", rv.String())
+			}
+		default:
+			return fmt.Errorf("cannot decode %T into %s", val, dst.Type())
+		}
+		if dst.OverflowInt(n) {
+			return fmt.Errorf("%v overflows %s", val, dst.Type())
+		}
+		dst.SetInt(n)
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		var n uint64
+		switch rv.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			if rv.Int() < 0 {
+				return fmt.Errorf("%v overflows %s", val, dst.Type())
+			}
+			n = uint64(rv.Int())
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			n = rv.Uint()
+		case reflect.Float32, reflect.Float64:
+			if rv.Float() < 0 || rv.Float() != math.Trunc(rv.Float()) {
+				return fmt.Errorf("%v is not an unsigned integer", val)
+			}
+			n = uint64(rv.Float())
+		case reflect.String:
+			var err error
+			if n, err = strconv.ParseUint(strings.TrimSpace(rv.String()), 10, 64); err != nil {
+				return fmt.Errorf("invalid unsigned integer %q", rv.String())
+			}
+		default:
+			return fmt.Errorf("cannot decode %T into %s", val, dst.Type())
+		}
+		if dst.OverflowUint(n) {
+			return fmt.Errorf("%v overflows %s", val, dst.Type())
+		}
+		dst.SetUint(n)
+
+	case reflect.Float32, reflect.Float64:
+		var f float64
+		switch rv.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			f = float64(rv.Int())
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			f = float64(rv.Uint())
+		case reflect.Float32, reflect.Float64:
+			f = rv.Float()
+		case reflect.String:
+			var err error
+			if f, err = strconv.ParseFloat(strings.TrimSpace(rv.String()), 64); err != nil {
+				return fmt.Errorf("invalid number %q", rv.String())
+			}
+		default:
+			return fmt.Errorf("cannot decode %T into %s", val, dst.Type())
+		}
+		dst.SetFloat(f)
+
+	case reflect.Bool:
+		switch rv.Kind() {
+		case reflect.Bool:
+			dst.SetBool(rv.Bool())
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			dst.SetBool(rv.Int() != 0)
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			dst.SetBool(rv.Uint() != 0)
+		case reflect.String:
+			b, err := strconv.ParseBool(strings.TrimSpace(rv.String()))
+			if err != nil {
+				return fmt.Errorf("invalid bool %q", rv.String())
+			}
+			dst.SetBool(b)
+		default:
+			return fmt.Errorf("cannot decode %T into %s", val, dst.Type())
+		}
+
+	default:
+		if rv.Type().AssignableTo(dst.Type()) {
+			dst.Set(rv)
+			return nil
+		}
+		return fmt.Errorf("cannot decode %T into %s", val, dst.Type())
+	}
+	return nil
+}
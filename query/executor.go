@@ -18,26 +18,54 @@ type QueryExecutor interface {
 	SetEmptyResult()
 }
 
-type queryExecutorBuilder func(ctx context.Context, config *config.ExecutorConfig) (QueryExecutor, error)
+// ExecutorBuilder 创建 query 执行器
+type ExecutorBuilder func(ctx context.Context, config *config.ExecutorConfig) (QueryExecutor, error)
 
-var queryExecutorBuilderMap = map[string]queryExecutorBuilder{}
+// Executors 执行器注册表, 每个 ApiJson 实例持有一个, 实例中未注册的使用全局注册的 (RegExecutor)
+type Executors struct {
+	builderMap map[string]ExecutorBuilder
+}
 
-func RegExecutor(name string, e queryExecutorBuilder) {
-	queryExecutorBuilderMap[name] = e
+func NewExecutors() *Executors {
+	return &Executors{builderMap: map[string]ExecutorBuilder{}}
 }
 
-func NewExecutor(name string, ctx context.Context, config *config.ExecutorConfig) (QueryExecutor, error) {
+// defaultExecutors 全局注册表, 作为各实例的默认值
+var defaultExecutors = NewExecutors()
+
+func (e *Executors) RegExecutor(name string, builder ExecutorBuilder) {
+	e.builderMap[name] = builder
+}
+
+func (e *Executors) NewExecutor(name string, ctx context.Context, config *config.ExecutorConfig) (QueryExecutor, error) {
 	if name == "" {
 		name = "default"
 	}
 
-	if v, exists := queryExecutorBuilderMap[name]; exists {
+	if v, exists := e.builderMap[name]; exists {
+		return v(ctx, config)
+	}
+
+	if v, exists := defaultExecutors.builderMap[name]; exists {
 		return v(ctx, config)
 	}
 
 	return nil, consts.NewSysErr("query executor not found: " + name)
 }
 
+func (e *Executors) List() []string {
+	return lo.Union(lo.Keys(defaultExecutors.builderMap), lo.Keys(e.builderMap))
+}
+
+// RegExecutor 全局注册执行器, 作为各实例的默认值
+func RegExecutor(name string, e ExecutorBuilder) {
+	defaultExecutors.RegExecutor(name, e)
+}
+
+func NewExecutor(name string, ctx context.Context, config *config.ExecutorConfig) (QueryExecutor, error) {
+	return defaultExecutors.NewExecutor(name, ctx, config)
+}
+
 func QueryExecutorList() []string {
-	return lo.Keys(queryExecutorBuilderMap)
+	return defaultExecutors.List()
 }
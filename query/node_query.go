@@ -47,7 +47,7 @@ func (q *queryNode) parse() {
 		}
 	}
 
-	var accessWhereCondition model.MapStrAny
+	condition := config.NewConditionRet()
 
 	setNodeRole(n, n.Key, n.role)
 	n.executorConfig.SetRole(n.role)
@@ -58,7 +58,7 @@ func (q *queryNode) parse() {
 	}
 
 	if n.queryContext.NoAccessVerify == false {
-		has, condition, err := hasAccess(n)
+		has, accessCondition, err := hasAccess(n)
 		if err != nil {
 			n.err = err
 			return
@@ -69,10 +69,40 @@ func (q *queryNode) parse() {
 			return
 		}
 
-		accessWhereCondition = condition.Where()
+		condition = accessCondition
 	}
 
-	queryExecutor, err := NewExecutor(n.executorConfig.Executor(), n.ctx, n.executorConfig)
+	// 软删除, 默认排除已删除的行
+	if accessConfig.SoftDeleteColumn != "" {
+		if gconv.Bool(n.req[consts.Deleted]) {
+			if !n.queryContext.NoAccessVerify && !accessConfig.IsRole(n.role, consts.ADMIN) {
+				n.err = consts.NewNoAccessErr(n.Key+"."+consts.Deleted, n.role)
+				return
+			}
+		} else {
+			condition.AddRaw(accessConfig.SoftDeleteColumn, nil)
+		}
+	}
+
+	// 多租户, 只能访问当前租户的行
+	if accessConfig.TenantColumn != "" {
+		err = accessConfig.CheckTenantKeys(n.ctx, n.req, n.queryContext.DbFieldStyle)
+		if err != nil {
+			n.err = err
+			return
+		}
+
+		tenantId, err := accessConfig.TenantId(n.ctx)
+		if err != nil {
+			n.err = err
+			return
+		}
+		condition.AddRaw(accessConfig.TenantColumn, tenantId)
+	}
+
+	accessWhereCondition := condition.Where()
+
+	queryExecutor, err := n.queryContext.executors().NewExecutor(n.executorConfig.Executor(), n.ctx, n.executorConfig)
 	if err != nil {
 		n.err = err
 		return
@@ -83,6 +113,18 @@ func (q *queryNode) parse() {
 	// 查询条件
 	refKeyMap, conditionMap, ctrlMap := parseQueryNodeReq(n.req, n.isList)
 
+	// 按表结构检查字段是否存在, 并转换值的类型
+	if table := n.queryContext.DbMeta.GetTable(accessConfig.Name); table != nil {
+		for k, v := range conditionMap {
+			val, err := table.CoerceReq(n.queryContext.DbFieldStyle(n.ctx, accessConfig.Name, k), v)
+			if err != nil {
+				n.err = consts.NewValidReqErr(n.Key + ": " + err.Error())
+				return
+			}
+			conditionMap[k] = val
+		}
+	}
+
 	n.executor.ParseCtrl(ctrlMap)
 
 	if v, exists := ctrlMap[consts.Column]; exists {
@@ -43,6 +43,9 @@ func (h *structNode) parse() {
 				hasPrimary = true
 				n.primaryTableKey = filepath.Base(child.Key)
 				child.page = n.page
+				if n.needTotal {
+					setNeedTotal(child)
+				}
 
 			}
 		}
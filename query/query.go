@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/glennliao/apijson-go/config"
+	"github.com/glennliao/apijson-go/consts"
 	"github.com/glennliao/apijson-go/model"
 	"github.com/glennliao/apijson-go/util"
 	"github.com/gogf/gf/v2/frame/g"
@@ -49,6 +50,9 @@ type Query struct {
 	// jsonFieldStyle 数据库返回的字段
 	JsonFieldStyle config.FieldStyle
 
+	// 执行器注册表, 为空时使用全局注册的
+	Executors *Executors
+
 	//Config *config.Config
 }
 
@@ -58,6 +62,10 @@ func New(ctx context.Context, qc *config.QueryConfig, req model.Map) *Query {
 		queryConfig: qc,
 	}
 	q.init(ctx, req)
+	if qc == nil {
+		q.err = consts.ErrNotLoaded
+		return q
+	}
 	q.NoAccessVerify = qc.NoVerify()
 
 	return q
@@ -72,8 +80,19 @@ func (q *Query) init(ctx context.Context, req model.Map) {
 	q.pathNodes = make(map[string]*Node)
 }
 
+func (q *Query) executors() *Executors {
+	if q.Executors == nil {
+		return defaultExecutors
+	}
+	return q.Executors
+}
+
 func (q *Query) Result() (model.Map, error) {
 
+	if q.err != nil {
+		return nil, q.err
+	}
+
 	if q.PrintProcessLog {
 		g.Log().Debugf(q.ctx, "【query】 ============ [begin]")
 		g.Log().Debugf(q.ctx, "【query】 ============ [buildNodeTree]")
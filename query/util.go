@@ -10,7 +10,6 @@ import (
 	"github.com/gogf/gf/v2/container/gset"
 	"github.com/gogf/gf/v2/frame/g"
 	"github.com/gogf/gf/v2/util/gconv"
-	"github.com/samber/lo"
 )
 
 // parseQueryNodeReq 解析节点请求内容
@@ -49,7 +48,7 @@ func hasAccess(node *Node) (hasAccess bool, condition *config.ConditionRet, err
 		return false, nil, err
 	}
 
-	if !lo.Contains(accessRoles, node.role) {
+	if !node.executorConfig.HasAccessRole(node.role) {
 		g.Log().Debug(node.ctx, node.Key, "role:", node.role, "accessRole", accessRoles, " -> deny")
 		return false, nil, err
 	}
@@ -63,6 +62,16 @@ func hasAccess(node *Node) (hasAccess bool, condition *config.ConditionRet, err
 		NodeReq:             node.req,
 		NodeRole:            node.role,
 	}, condition)
+	if err != nil {
+		return false, nil, err
+	}
+
+	err = node.executorConfig.RowPolicyWhere(node.ctx, node.role, condition)
+	if err != nil {
+		return false, nil, err
+	}
+
+	err = node.executorConfig.EvalRules(node.ctx, node.role, node.req, condition)
 
 	return true, condition, err
 }
@@ -76,9 +85,10 @@ func getColList(list []model.Map, col string) []any {
 	return set.Slice()
 }
 
+// setNeedTotal 结构节点未解析出主表时, 在解析时设置到主表
 func setNeedTotal(node *Node) {
 	node.needTotal = true
-	if node.Type == NodeTypeStruct {
+	if node.Type == NodeTypeStruct && node.primaryTableKey != "" {
 		setNeedTotal(node.children[node.primaryTableKey])
 	}
 }
@@ -117,6 +127,10 @@ func analysisRef(p *Node, prerequisites *[][]string) {
 		for _, refNode := range node.refKeyMap {
 			*prerequisites = append(*prerequisites, []string{node.Path, refNode.node.Path})
 		}
+		// 列表结构节点的 total 来自主表
+		if node.Type == NodeTypeStruct && node.needTotal && node.primaryTableKey != "" {
+			*prerequisites = append(*prerequisites, []string{node.Path, node.children[node.primaryTableKey].Path})
+		}
 		analysisRef(node, prerequisites)
 	}
 }
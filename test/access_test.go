@@ -0,0 +1,255 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/glennliao/apijson-go"
+	"github.com/glennliao/apijson-go/config"
+	"github.com/glennliao/apijson-go/consts"
+	"github.com/glennliao/apijson-go/model"
+	"github.com/gogf/gf/v2/os/gctx"
+	"github.com/gogf/gf/v2/util/gconv"
+)
+
+// accessApi 使用 user 表的 _access 配置, 节点角色为请求中的 @role, $ctx 的值从 ctxValues 获取
+func accessApi(t *testing.T, access config.AccessConfig, ctxValues map[string]any) *apijson.ApiJson {
+	config.RegAccessListProvider(t.Name(), func(ctx context.Context) []config.AccessConfig {
+		access := access
+		access.Name, access.Alias, access.RowKey = "user", "User", "id"
+		access.FieldsGet = map[string]*config.FieldsGetValue{"default": {In: map[string][]string{"id": {"*"}}}}
+		return []config.AccessConfig{access}
+	})
+
+	s := apijson.New()
+	s.Config().AccessListProvider = t.Name()
+	s.Config().Access.DefaultRoleFunc = func(ctx context.Context, req config.RoleReq) (string, error) {
+		if req.NodeRole != "" {
+			return req.NodeRole, nil
+		}
+		return "UNKNOWN", nil
+	}
+	s.Config().Access.CtxValueFunc = func(ctx context.Context, key string) (any, error) {
+		return ctxValues[key], nil
+	}
+	s.Load()
+	return s
+}
+
+// userIds 按角色查询 User[] 的 id
+func userIds(t *testing.T, s *apijson.ApiJson, role string, cond model.Map) ([]int64, error) {
+	req := model.Map{"@column": "id", "@role": role}
+	for k, v := range cond {
+		req[k] = v
+	}
+	ret, err := s.NewQuery(gctx.New(), model.Map{"User[]": req}).Result()
+	if err != nil {
+		return nil, err
+	}
+	var ids []int64
+	for _, row := range gconv.Maps(ret["User[]"]) {
+		ids = append(ids, gconv.Int64(row["id"]))
+	}
+	return ids, nil
+}
+
+func allUserIds(t *testing.T) []int64 {
+	q := a.NewQuery(gctx.New(), model.Map{"User[]": model.Map{"@column": "id"}})
+	q.NoAccessVerify = true
+	ret, err := q.Result()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ids []int64
+	for _, row := range gconv.Maps(ret["User[]"]) {
+		ids = append(ids, gconv.Int64(row["id"]))
+	}
+	if len(ids) < 2 {
+		t.Fatal("need rows", ids)
+	}
+	return ids
+}
+
+func TestRowPolicy(t *testing.T) {
+	all := allUserIds(t)
+	first, last := all[0], all[len(all)-1]
+
+	s := accessApi(t, config.AccessConfig{
+		Get: []string{"OWNER", "ORG"},
+		RowPolicy: map[string]map[string][]string{
+			// 同一字段的条件均生效
+			"OWNER": {"*": {"id = $ctx.userId"}, "get": {"id IN $ctx.ids"}},
+			"ORG":   {"get": {"id = $ctx.missing"}},
+		},
+	}, map[string]any{"userId": first, "ids": []int64{first, last}})
+
+	ids, err := userIds(t, s, "OWNER", nil)
+	if err != nil || len(ids) != 1 || ids[0] != first {
+		t.Fatal("OWNER", ids, err)
+	}
+
+	s.Config().Access.CtxValueFunc = func(ctx context.Context, key string) (any, error) {
+		return map[string]any{"userId": first, "ids": []int64{last}}[key], nil
+	}
+	ids, err = userIds(t, s, "OWNER", nil)
+	if err != nil || len(ids) != 0 {
+		t.Fatal("OWNER with disjoint conditions", ids, err)
+	}
+
+	// 上下文的值为空时不匹配任何行
+	ids, err = userIds(t, s, "ORG", nil)
+	if err != nil || len(ids) != 0 {
+		t.Fatal("ORG deny all", ids, err)
+	}
+}
+
+func TestAccessRulesCompile(t *testing.T) {
+	check := func(when string) error {
+		return config.CheckAccessConfig(config.AccessConfig{Name: "user", Alias: "User",
+			Rules: []*config.AccessRule{{When: when, Deny: true}}})
+	}
+
+	for _, when := range []string{
+		strings.Repeat("(", 40) + "true" + strings.Repeat(")", 40), // 嵌套过深
+		strings.Repeat("!", 40) + "true",
+		"true" + strings.Repeat(" ", 1024), // 过长
+		"'a' in 'abc'",                     // in 只用于列表
+		"role in lower('ADMIN')",
+		"contains('abc', 'a')",
+	} {
+		if err := check(when); err == nil {
+			t.Errorf("%.40s: need error", when)
+		}
+	}
+
+	for _, when := range []string{
+		strings.Repeat("(", 10) + "true" + strings.Repeat(")", 10),
+		"role in ['ADMIN', 'OWNER'] && contains(principal.orgIds, 'o1')",
+	} {
+		if err := check(when); err != nil {
+			t.Errorf("%s: %v", when, err)
+		}
+	}
+
+	// 不修改调用方的配置
+	rules := []*config.AccessRule{{When: "true", Methods: []string{"get"}, Deny: true}}
+	if err := config.CheckAccessConfig(config.AccessConfig{Name: "user", Alias: "User", Rules: rules}); err != nil {
+		t.Fatal(err)
+	}
+	if rules[0].Methods[0] != "get" {
+		t.Fatal("rule methods modified", rules[0].Methods)
+	}
+}
+
+func TestAccessRulesWhere(t *testing.T) {
+	all := allUserIds(t)
+	first, last := all[0], all[len(all)-1]
+
+	s := accessApi(t, config.AccessConfig{
+		Get:       []string{"OWNER"},
+		RowPolicy: map[string]map[string][]string{"OWNER": {"get": {"id = $ctx.userId"}}},
+		Rules:     []*config.AccessRule{{When: "req.tag == null", Where: []string{"id = $ctx.other"}}},
+	}, map[string]any{"userId": first, "other": last})
+
+	// 规则的条件与行级策略同时生效
+	ids, err := userIds(t, s, "OWNER", nil)
+	if err != nil || len(ids) != 0 {
+		t.Fatal(ids, err)
+	}
+
+	s.Config().Access.CtxValueFunc = func(ctx context.Context, key string) (any, error) {
+		return map[string]any{"userId": first, "other": first}[key], nil
+	}
+	ids, err = userIds(t, s, "OWNER", nil)
+	if err != nil || len(ids) != 1 || ids[0] != first {
+		t.Fatal(ids, err)
+	}
+}
+
+func TestRoleInherit(t *testing.T) {
+	ctx := gctx.New()
+
+	s := accessApi(t, config.AccessConfig{Get: []string{"OWNER"}}, nil)
+	s.Config().Access.AddRole([]string{"OTHER"}).RoleInherit("SUPER", "SUPPORT").RoleInherit("SUPPORT", "OWNER")
+	if err := s.Reload(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	// 间接继承的角色同样可访问
+	for _, role := range []string{"OWNER", "SUPPORT", "SUPER"} {
+		if _, err := userIds(t, s, role, nil); err != nil {
+			t.Fatal(role, err)
+		}
+	}
+	if _, err := userIds(t, s, "OTHER", nil); err == nil {
+		t.Fatal("OTHER: need error")
+	}
+
+	// 继承有环时加载失败, 保留原配置
+	s.Config().Access.RoleInherit("OWNER", "SUPER")
+	if err := s.Reload(ctx); err == nil || !strings.Contains(err.Error(), "cycle") {
+		t.Fatal("cycle", err)
+	}
+	if _, err := userIds(t, s, "SUPER", nil); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestAccessRulesInherit(t *testing.T) {
+	all := allUserIds(t)
+	first := all[0]
+
+	s := accessApi(t, config.AccessConfig{
+		Get:   []string{"OWNER"},
+		Rules: []*config.AccessRule{{Roles: []string{"OWNER"}, When: "req.tag == null", Where: []string{"id = $ctx.userId"}}},
+	}, map[string]any{"userId": first})
+	s.Config().Access.RoleInherit("SUPPORT", "OWNER")
+	if err := s.Reload(gctx.New()); err != nil {
+		t.Fatal(err)
+	}
+
+	// 通过继承获得访问权限的角色同样受规则限制
+	ids, err := userIds(t, s, "SUPPORT", nil)
+	if err != nil || len(ids) != 1 || ids[0] != first {
+		t.Fatal(ids, err)
+	}
+}
+
+func TestRowPolicyInherit(t *testing.T) {
+	all := allUserIds(t)
+	first := all[0]
+
+	s := accessApi(t, config.AccessConfig{
+		Get:       []string{"OWNER"},
+		RowPolicy: map[string]map[string][]string{"OWNER": {"*": {"id = $ctx.userId"}}},
+	}, map[string]any{"userId": first})
+	s.Config().Access.RoleInherit("SUPPORT", "OWNER")
+	if err := s.Reload(gctx.New()); err != nil {
+		t.Fatal(err)
+	}
+
+	// 通过继承获得访问权限的角色使用被继承角色的策略
+	ids, err := userIds(t, s, "SUPPORT", nil)
+	if err != nil || len(ids) != 1 || ids[0] != first {
+		t.Fatal(ids, err)
+	}
+}
+
+func TestConditionRetRaw(t *testing.T) {
+	condition := config.NewConditionRet()
+	condition.AddRaw("id", 1)
+	condition.AddRaw("user_id", 2)
+	condition.AddRaw("id", 3)
+
+	// consts.Raw 保持 map, 同一字段重复的条件在 consts.RawAnd 中
+	where := condition.Where()
+	raw, ok := where[consts.Raw].(map[string]any)
+	if !ok || len(raw) != 2 || raw["id"] != 1 || raw["user_id"] != 2 {
+		t.Fatal(where)
+	}
+	rawAnd, ok := where[consts.RawAnd].([]map[string]any)
+	if !ok || len(rawAnd) != 1 || rawAnd[0]["id"] != 3 {
+		t.Fatal(where)
+	}
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/glennliao/apijson-go"
+	"github.com/glennliao/apijson-go/action"
+	"github.com/glennliao/apijson-go/config"
+	"github.com/glennliao/apijson-go/model"
+	"github.com/gogf/gf/v2/os/gctx"
+)
+
+// countExecutor 只返回总数, 不返回 count[]
+type countExecutor struct{}
+
+func (countExecutor) Do(ctx context.Context, req action.ActionExecutorReq) (model.Map, error) {
+	return model.Map{"code": 200, "count": len(req.Where)}, nil
+}
+
+func TestAffectedWithoutCountList(t *testing.T) {
+	ctx := gctx.New()
+
+	s := apijson.New()
+	s.Config().RegAccessListProvider("affected", func(ctx context.Context) []config.AccessConfig {
+		return []config.AccessConfig{{Name: "memo", Alias: "Memo", Put: []string{"UNKNOWN"}, RowKey: "id"}}
+	})
+	s.Config().RegRequestListProvider("affected", func(ctx context.Context) []config.RequestConfig {
+		return []config.RequestConfig{{Tag: "Memo[]", Method: http.MethodPut, Version: "1", ExecQueue: []string{"Memo[]"},
+			Executor: map[string]string{"Memo[]": "count"}, Structure: map[string]*config.Structure{"Memo[]": {Affected: "1"}}}}
+	})
+	s.Config().AccessListProvider = "affected"
+	s.Config().RequestListProvider = "affected"
+	s.ActionRegistry().RegExecutor("count", countExecutor{})
+	s.Load()
+
+	put := func(rows ...model.Map) error {
+		_, err := s.NewAction(ctx, http.MethodPut, model.Map{"tag": "Memo[]", "Memo[]": rows}).Result()
+		return err
+	}
+
+	if err := put(model.Map{"id": 1, "title": "a"}); err != nil {
+		t.Fatal(err)
+	}
+	// 多项时无法得知每一项的影响行数
+	if err := put(model.Map{"id": 1, "title": "a"}, model.Map{"id": 2, "title": "b"}); err == nil {
+		t.Fatal("need count[]")
+	}
+}
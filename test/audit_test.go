@@ -0,0 +1,110 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/glennliao/apijson-go"
+	"github.com/glennliao/apijson-go/action"
+	"github.com/glennliao/apijson-go/config"
+	"github.com/glennliao/apijson-go/model"
+	"github.com/gogf/gf/v2/database/gdb"
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/os/gctx"
+	"github.com/gogf/gf/v2/util/gconv"
+)
+
+// memoryAuditSink 记录审计记录及是否在事务中写入, err 不为空时写入失败
+type memoryAuditSink struct {
+	records []action.AuditRecord
+	inTx    bool
+	err     error
+}
+
+func (s *memoryAuditSink) Write(ctx context.Context, records []action.AuditRecord) error {
+	if s.err != nil {
+		return s.err
+	}
+	s.records = append(s.records, records...)
+	s.inTx = gdb.TXFromCtx(ctx, g.DB().GetGroup()) != nil
+	return nil
+}
+
+func TestAudit(t *testing.T) {
+	ctx := gctx.New()
+	all := []string{"UNKNOWN"}
+
+	config.RegAccessListProvider("audit", func(ctx context.Context) []config.AccessConfig {
+		return []config.AccessConfig{{Name: "audit_doc", Alias: "AuditDoc", Put: all, Delete: all, RowKey: "id", Audit: true}}
+	})
+	config.RegRequestListProvider("audit", func(ctx context.Context) []config.RequestConfig {
+		var list []config.RequestConfig
+		for _, method := range []string{http.MethodPut, http.MethodDelete} {
+			list = append(list, config.RequestConfig{Tag: "AuditDoc", Method: method, Version: "1", Structure: map[string]*config.Structure{"AuditDoc": {}}})
+		}
+		return list
+	})
+
+	for _, sql := range []string{
+		"CREATE TABLE IF NOT EXISTS audit_doc (id integer primary key, title text)",
+		"DELETE FROM audit_doc",
+		"INSERT INTO audit_doc (id, title) VALUES (1, 'a'), (2, 'b')",
+	} {
+		if _, err := g.DB().Exec(ctx, sql); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	sink := &memoryAuditSink{}
+	s := apijson.New()
+	s.Config().AccessListProvider = "audit"
+	s.Config().RequestListProvider = "audit"
+	s.ActionRegistry().RegAuditSink(sink)
+	s.Load()
+
+	title := func(id int) string {
+		v, err := g.DB().GetValue(ctx, "SELECT title FROM audit_doc WHERE id = ?", id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return v.String()
+	}
+
+	// put 记录修改前后的行
+	_, err := s.NewAction(ctx, http.MethodPut, model.Map{"tag": "AuditDoc", "AuditDoc": model.Map{"id": 1, "title": "x"}}).Result()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sink.records) != 1 || !sink.inTx {
+		t.Fatal(sink.records, sink.inTx)
+	}
+	record := sink.records[0]
+	if record.Method != http.MethodPut || gconv.Int(record.RowKey) != 1 || record.Before["title"] != "a" || record.After["title"] != "x" {
+		t.Fatal(record)
+	}
+
+	// delete 只有修改前的行
+	_, err = s.NewAction(ctx, http.MethodDelete, model.Map{"tag": "AuditDoc", "AuditDoc": model.Map{"id": 2}}).Result()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sink.records) != 2 || !sink.inTx {
+		t.Fatal(sink.records, sink.inTx)
+	}
+	record = sink.records[1]
+	if record.Method != http.MethodDelete || gconv.Int(record.RowKey) != 2 || record.Before["title"] != "b" || record.After != nil {
+		t.Fatal(record)
+	}
+
+	// 审计写入失败时回滚修改
+	sink.err = errors.New("sink failed")
+	_, err = s.NewAction(ctx, http.MethodPut, model.Map{"tag": "AuditDoc", "AuditDoc": model.Map{"id": 1, "title": "y"}}).Result()
+	if err == nil {
+		t.Fatal("sink error")
+	}
+	if title(1) != "x" {
+		t.Fatal(title(1))
+	}
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/glennliao/apijson-go"
+	"github.com/glennliao/apijson-go/config"
+	"github.com/glennliao/apijson-go/model"
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/os/gctx"
+)
+
+func TestAutoFillFromCondition(t *testing.T) {
+	ctx := gctx.New()
+
+	config.RegAccessListProvider("autofill", func(ctx context.Context) []config.AccessConfig {
+		return []config.AccessConfig{{Name: "fill", Alias: "Fill", Post: []string{"UNKNOWN"}, RowKey: "id",
+			AutoFill: map[string]*config.AutoFillValue{"created_by": {Post: "sys"}}}}
+	})
+	config.RegRequestListProvider("autofill", func(ctx context.Context) []config.RequestConfig {
+		return []config.RequestConfig{{Tag: "Fill", Method: http.MethodPost, Version: "1", Structure: map[string]*config.Structure{"Fill": {}}}}
+	})
+
+	for _, sql := range []string{
+		"CREATE TABLE IF NOT EXISTS fill (id integer primary key autoincrement, title text, created_by text)",
+		"DELETE FROM fill",
+	} {
+		if _, err := g.DB().Exec(ctx, sql); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	s := apijson.New()
+	s.Config().AccessListProvider = "autofill"
+	s.Config().RequestListProvider = "autofill"
+	s.Config().Access.ConditionFunc = func(ctx context.Context, req config.ConditionReq, condition *config.ConditionRet) error {
+		condition.Add("created_by", "cond")
+		return nil
+	}
+	s.Load()
+
+	// access condition 写入的自动填充字段不视为前端传入
+	_, err := s.NewAction(ctx, http.MethodPost, model.Map{"tag": "Fill", "Fill": model.Map{"title": "a"}}).Result()
+	if err != nil {
+		t.Fatal(err)
+	}
+	createdBy, err := g.DB().GetValue(ctx, "SELECT created_by FROM fill")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if createdBy.String() != "sys" {
+		t.Fatal(createdBy)
+	}
+
+	_, err = s.NewAction(ctx, http.MethodPost, model.Map{"tag": "Fill", "Fill": model.Map{"title": "b", "createdBy": "x"}}).Result()
+	if err == nil {
+		t.Fatal("post with auto fill column")
+	}
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/glennliao/apijson-go/client"
+	"github.com/glennliao/apijson-go/consts"
+	"github.com/glennliao/apijson-go/model"
+	"github.com/gogf/gf/v2/os/gctx"
+)
+
+func TestClientBuilder(t *testing.T) {
+	req, err := client.NewRequest(
+		client.Table("User").Eq("id", 1).Column("id", "username:name"),
+		client.ListOf(
+			client.Table("User").Range("id", ">=1", "<10").Order("id-"),
+			client.List("Todo").Ref("userId", "/User/id").NotIn("id", 3),
+		).Page(1).Count(10).Total(),
+		client.Ref("total", "[]/total"),
+	).Map()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expect := model.Map{
+		"User": model.Map{"id": 1, "@column": "id,username:name"},
+		"[]": model.Map{
+			"page": 1, "count": 10, "query": 2,
+			"User":   model.Map{"id&{}": ">=1,<10", "@order": "id-"},
+			"Todo[]": model.Map{"userId@": "/User/id", "id!{}": []any{3}},
+		},
+		"total@": "[]/total",
+	}
+	if !reflect.DeepEqual(req, expect) {
+		t.Fatal(req)
+	}
+
+	for msg, r := range map[string]*client.Request{
+		"user: table alias":          client.NewRequest(client.Table("user")),
+		`User: invalid field "id{}"`: client.NewRequest(client.Table("User").Eq("id{}", 1)),
+		"User: page only for list":   client.NewRequest(client.Table("User").Page(1)),
+		"User.id: duplicate key":     client.NewRequest(client.Table("User").Eq("id", 1).Eq("id", 2)),
+		"[]: list must have a table": client.NewRequest(client.ListOf(client.Ref("total", "[]/total"))),
+		"User: duplicate node":       client.NewRequest(client.Table("User"), client.Table("User")),
+		`User: invalid key "a b"`:    client.NewRequest(client.Table("User").Set("a b", 1)),
+		"User[].1: username.1":       client.NewRequest(client.Rows("User", client.Row(), client.Row().Column("username.1"))),
+	} {
+		if _, err = r.Map(); err == nil || !strings.Contains(err.Error(), strings.Split(msg, ".1: ")[0]) {
+			t.Fatal(msg, err)
+		}
+	}
+}
+
+func TestClient(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req model.Map
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		if r.URL.Path != "/api/get" || r.Header.Get("Authorization") != "token" {
+			_ = json.NewEncoder(w).Encode(model.Map{"ok": false, "code": 403, "msg": "deny"})
+			return
+		}
+		q := a.NewQuery(r.Context(), req)
+		q.NoAccessVerify = true
+		ret, err := q.Result()
+		if err != nil {
+			_ = json.NewEncoder(w).Encode(model.Map{"ok": false, "code": 400, "msg": err.Error()})
+			return
+		}
+		_ = json.NewEncoder(w).Encode(model.Map{"ok": true, "code": 200, "msg": "success", "data": ret})
+	}))
+	defer server.Close()
+
+	ctx := gctx.New()
+	c := client.NewClient(server.URL + "/api/")
+	c.Header.Set("Authorization", "token")
+
+	ret, err := c.Get(ctx, client.NewRequest(client.List("User").Range("id", ">=0").NotIn("id", 0).Count(2)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := ret["User[]"]; !ok {
+		t.Fatal(ret)
+	}
+
+	_, err = c.Post(ctx, client.NewRequest(client.Table("User").Eq("username", "a")).Tag("User"))
+	if e, ok := err.(consts.Err); !ok || e.Code() != 403 || e.Error() != "deny" {
+		t.Fatal(err)
+	}
+}
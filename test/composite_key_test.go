@@ -0,0 +1,106 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/glennliao/apijson-go"
+	"github.com/glennliao/apijson-go/config"
+	"github.com/glennliao/apijson-go/consts"
+	"github.com/glennliao/apijson-go/model"
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/os/gctx"
+	"github.com/gogf/gf/v2/util/gconv"
+)
+
+func TestCompositeRowKey(t *testing.T) {
+	ctx := gctx.New()
+	all := []string{"UNKNOWN"}
+
+	config.RegAccessListProvider("compositeKey", func(ctx context.Context) []config.AccessConfig {
+		return []config.AccessConfig{
+			{Name: "user_role_link", Alias: "UserRoleLink", Put: all, Delete: all, RowKey: "user_id,role_id"},
+			{Name: "user_role_link", Alias: "UserRoleGen", Post: all, RowKey: "user_id,role_id", RowKeyGen: "pair"},
+		}
+	})
+	config.RegRequestListProvider("compositeKey", func(ctx context.Context) []config.RequestConfig {
+		list := []config.RequestConfig{{Tag: "UserRoleGen", Method: http.MethodPost, Version: "1", Structure: map[string]*config.Structure{"UserRoleGen": {}}}}
+		for _, method := range []string{http.MethodPut, http.MethodDelete} {
+			list = append(list, config.RequestConfig{Tag: "UserRoleLink", Method: method, Version: "1", Structure: map[string]*config.Structure{"UserRoleLink": {}}})
+		}
+		return list
+	})
+
+	for _, sql := range []string{
+		"CREATE TABLE IF NOT EXISTS user_role_link (user_id integer, role_id integer, note text, PRIMARY KEY (user_id, role_id))",
+		"DELETE FROM user_role_link",
+		"INSERT INTO user_role_link (user_id, role_id, note) VALUES (1, 1, 'a'), (1, 2, 'b'), (2, 1, 'c')",
+	} {
+		if _, err := g.DB().Exec(ctx, sql); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	s := apijson.New()
+	s.Config().AccessListProvider = "compositeKey"
+	s.Config().RequestListProvider = "compositeKey"
+	s.Config().RowKeyGenFunc("pair", func(ctx context.Context, req *config.RowKeyGenReq, ret *config.RowKeyGenRet) error {
+		ret.RowKeys(model.Map{"user_id": 7, "role_id": 8})
+		return nil
+	})
+	s.Load()
+
+	notes := func() map[string]string {
+		rows, err := g.DB().GetAll(ctx, "SELECT user_id, role_id, note FROM user_role_link")
+		if err != nil {
+			t.Fatal(err)
+		}
+		m := map[string]string{}
+		for _, row := range rows {
+			m[row["user_id"].String()+","+row["role_id"].String()] = row["note"].String()
+		}
+		return m
+	}
+
+	// RowKeyGen 设置每个主键字段, 返回 字段->值
+	ret, err := s.NewAction(ctx, http.MethodPost, model.Map{"tag": "UserRoleGen", "UserRoleGen": model.Map{"note": "g"}}).Result()
+	if err != nil {
+		t.Fatal(err)
+	}
+	id := gconv.Map(gconv.Map(ret["UserRoleGen"])[consts.Id])
+	if gconv.Int(id["userId"]) != 7 || gconv.Int(id["roleId"]) != 8 || notes()["7,8"] != "g" {
+		t.Fatal(ret, notes())
+	}
+
+	// 各主键字段均作为条件
+	_, err = s.NewAction(ctx, http.MethodPut, model.Map{"tag": "UserRoleLink", "UserRoleLink": model.Map{"userId": 1, "roleId": 2, "note": "x"}}).Result()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m := notes(); m["1,2"] != "x" || m["1,1"] != "a" || m["2,1"] != "c" {
+		t.Fatal(m)
+	}
+
+	// 缺少部分主键字段
+	_, err = s.NewAction(ctx, http.MethodPut, model.Map{"tag": "UserRoleLink", "UserRoleLink": model.Map{"userId": 1, "note": "y"}}).Result()
+	if err == nil {
+		t.Fatal("put without roleId")
+	}
+	_, err = s.NewAction(ctx, http.MethodDelete, model.Map{"tag": "UserRoleLink", "UserRoleLink": model.Map{"roleId": 1}}).Result()
+	if err == nil {
+		t.Fatal("delete without userId")
+	}
+	if m := notes(); len(m) != 4 || m["1,1"] != "a" {
+		t.Fatal(m)
+	}
+
+	// 主键值的列表
+	ret, err = s.NewAction(ctx, http.MethodDelete, model.Map{"tag": "UserRoleLink", "UserRoleLink": model.Map{"userId,roleId{}": [][]int{{1, 1}, {2, 1}}}}).Result()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m := notes(); len(m) != 2 || m["1,2"] != "x" || m["7,8"] != "g" {
+		t.Fatal(m)
+	}
+}
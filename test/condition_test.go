@@ -0,0 +1,50 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/glennliao/apijson-go/model"
+	"github.com/gogf/gf/v2/os/gctx"
+	"github.com/gogf/gf/v2/util/gconv"
+)
+
+func TestMultiCondition(t *testing.T) {
+	ctx := gctx.New()
+
+	ids := func(cond model.Map) []int64 {
+		cond["@column"] = "id"
+		q := a.NewQuery(ctx, model.Map{"User[]": cond})
+		q.NoAccessVerify = true
+		ret, err := q.Result()
+		if err != nil {
+			t.Fatal(cond, err)
+		}
+		var list []int64
+		for _, row := range ret["User[]"].([]model.Map) {
+			list = append(list, gconv.Int64(row["id"]))
+		}
+		return list
+	}
+
+	all := ids(model.Map{})
+	if len(all) < 2 {
+		t.Fatal("need rows", all)
+	}
+	first, last := all[0], all[len(all)-1]
+
+	for _, id := range ids(model.Map{"id&{}": ">" + gconv.String(first) + ",<=" + gconv.String(last)}) {
+		if id <= first || id > last {
+			t.Fatal("&{}", id)
+		}
+	}
+	for _, id := range ids(model.Map{"id|{}": "<=" + gconv.String(first) + ",>=" + gconv.String(last)}) {
+		if id != first && id != last {
+			t.Fatal("|{}", id)
+		}
+	}
+	for _, id := range ids(model.Map{"id!{}": []any{first}}) {
+		if id == first {
+			t.Fatal("!{}", id)
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/glennliao/apijson-go"
+	"github.com/glennliao/apijson-go/config"
+	"github.com/glennliao/apijson-go/model"
+	"github.com/gogf/gf/v2/os/gctx"
+)
+
+func TestDbMeta(t *testing.T) {
+	table := a.Config().Snapshot().DbMeta().GetTable("_access")
+	if table == nil || len(table.PrimaryKey) != 1 || table.PrimaryKey[0] != "id" {
+		t.Fatal("primary key", table)
+	}
+	if keys := table.UniqueKeys(); len(keys) != 2 || keys[1][0] != "alias" {
+		t.Fatal("unique keys", keys)
+	}
+	for column, kind := range map[string]string{"id": config.KindInt, "name": config.KindString, "fields_get": config.KindJson, "created_at": config.KindTime} {
+		if table.Column(column).Kind() != kind {
+			t.Fatal("kind", column, table.Column(column))
+		}
+	}
+	if table.Column("name").Nullable || !table.Column("alias").Nullable {
+		t.Fatal("nullable")
+	}
+
+	for _, c := range []struct {
+		typ    string
+		val    any
+		expect any
+	}{
+		{"bigint", "12", int64(12)},
+		{"int(11)", float64(3), int64(3)},
+		{"varchar(32)", float64(12), "12"},
+		{"tinyint(1)", true, 1},
+		{"decimal(10,2)", "1.50", "1.50"},
+		{"boolean", "1", true},
+	} {
+		column := config.Column{Type: c.typ}
+		val, err := column.Coerce(c.val)
+		if err != nil || val != c.expect {
+			t.Fatal(c.typ, c.val, val, err)
+		}
+	}
+	if _, err := (&config.Column{Type: "int"}).Coerce("1a"); err == nil {
+		t.Fatal("invalid int")
+	}
+}
+
+func TestDbMetaReq(t *testing.T) {
+	ctx := gctx.New()
+
+	s := apijson.New()
+	s.Config().RegAccessListProvider("instance", func(ctx context.Context) []config.AccessConfig {
+		return []config.AccessConfig{{Name: "todo", Alias: "Todo", Get: []string{"UNKNOWN"}}}
+	})
+	s.Config().RegRequestListProvider("instance", func(ctx context.Context) []config.RequestConfig {
+		return []config.RequestConfig{{Tag: "Todo", Method: http.MethodPost, Version: "1", Structure: map[string]*config.Structure{"Todo": {}}}}
+	})
+	s.Config().AccessListProvider = "instance"
+	s.Config().RequestListProvider = "instance"
+	s.Load()
+
+	// 未配置主键时使用表的主键
+	access, err := s.Config().QueryConfig().GetAccessConfig("Todo", true)
+	if err != nil || access.RowKey != "id" {
+		t.Fatal("row key", access, err)
+	}
+
+	q := s.NewQuery(ctx, model.Map{"Todo": model.Map{"userId": "1", "id{}": []any{"1", 2}}})
+	q.NoAccessVerify = true
+	if _, err = q.Result(); err != nil {
+		t.Fatal(err)
+	}
+
+	for expect, req := range map[string]model.Map{
+		"不存在的字段 title": {"Todo": model.Map{"title": "a"}},
+		"user_id 类型错误": {"Todo": model.Map{"userId": "abc"}},
+	} {
+		q = s.NewQuery(ctx, req)
+		q.NoAccessVerify = true
+		_, err = q.Result()
+		if err == nil || !strings.Contains(err.Error(), expect) {
+			t.Fatal(expect, err)
+		}
+	}
+
+	act := s.NewAction(ctx, http.MethodPost, model.Map{"Todo": model.Map{"title": "a"}, "tag": "Todo"})
+	act.NoAccessVerify = true
+	if _, err = act.Result(); err == nil || !strings.Contains(err.Error(), "不存在的字段 title") {
+		t.Fatal("action", err)
+	}
+}
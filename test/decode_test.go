@@ -0,0 +1,62 @@
+package main
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/glennliao/apijson-go/model"
+	"github.com/glennliao/apijson-go/query"
+	"github.com/gogf/gf/v2/os/gctx"
+	"github.com/gogf/gf/v2/os/gtime"
+)
+
+func TestResultInto(t *testing.T) {
+	ctx := gctx.New()
+
+	type Row struct {
+		Id        uint32
+		Name      string `json:"username"`
+		CreatedAt *time.Time
+		UpdatedAt *gtime.Time
+	}
+	type Result struct {
+		User  *Row
+		Users []Row `apijson:"User[]"`
+		List  []struct {
+			User Row
+		} `apijson:"[]"`
+		Total int `apijson:"total"`
+	}
+
+	req := model.Map{
+		"User":   model.Map{"id": 4},
+		"User[]": model.Map{"count": 2},
+		"[]":     model.Map{"User": model.Map{}, "count": 3, "query": 2},
+		"total@": "[]/total",
+	}
+	q := a.NewQuery(ctx, req)
+	q.NoAccessVerify = true
+	ret, err := query.ResultAs[Result](q)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ret.User == nil || ret.User.Id != 4 || ret.User.Name == "" || len(ret.Users) != 2 || len(ret.List) != 3 || ret.List[0].User.Id == 0 || ret.Total < 3 {
+		t.Fatal(ret)
+	}
+
+	var bad struct {
+		List []struct {
+			User struct {
+				Username int
+			}
+		} `apijson:"[]"`
+	}
+	q = a.NewQuery(ctx, model.Map{"[]": model.Map{"User": model.Map{"@column": "username"}, "count": 1}})
+	q.NoAccessVerify = true
+	err = q.ResultInto(&bad)
+	var decodeErr *query.DecodeError
+	if !errors.As(err, &decodeErr) || decodeErr.Path != "[]/0/User/username" {
+		t.Fatal(err)
+	}
+}
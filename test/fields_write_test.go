@@ -0,0 +1,100 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/glennliao/apijson-go"
+	"github.com/glennliao/apijson-go/config"
+	"github.com/glennliao/apijson-go/consts"
+	"github.com/glennliao/apijson-go/model"
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/os/gctx"
+	"github.com/gogf/gf/v2/util/gconv"
+)
+
+func TestFieldsWrite(t *testing.T) {
+	ctx := gctx.New()
+	roles := []string{"OWNER", "ADMIN"}
+
+	config.RegAccessListProvider("fieldsWrite", func(ctx context.Context) []config.AccessConfig {
+		return []config.AccessConfig{{Name: "fw_doc", Alias: "FwDoc", Post: roles, Put: roles, RowKey: "id",
+			FieldsPost: map[string][]string{"OWNER": {"title"}, "ADMIN": {"*"}},
+			FieldsPut:  map[string][]string{"OWNER": {"title"}, "ADMIN": {"*"}},
+		}}
+	})
+	config.RegRequestListProvider("fieldsWrite", func(ctx context.Context) []config.RequestConfig {
+		var list []config.RequestConfig
+		for _, method := range []string{http.MethodPost, http.MethodPut} {
+			list = append(list, config.RequestConfig{Tag: "FwDoc", Method: method, Version: "1", Structure: map[string]*config.Structure{"FwDoc": {}}})
+		}
+		return list
+	})
+
+	for _, sql := range []string{
+		"CREATE TABLE IF NOT EXISTS fw_doc (id integer primary key autoincrement, title text, secret text, user_id text)",
+		"DELETE FROM fw_doc",
+	} {
+		if _, err := g.DB().Exec(ctx, sql); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	s := apijson.New()
+	s.Config().AccessListProvider = "fieldsWrite"
+	s.Config().RequestListProvider = "fieldsWrite"
+	s.Config().Access.RoleInherit("SUPPORT", "OWNER")
+	s.Config().Access.DefaultRoleFunc = func(ctx context.Context, req config.RoleReq) (string, error) {
+		return req.NodeRole, nil
+	}
+	s.Config().Access.ConditionFunc = func(ctx context.Context, req config.ConditionReq, condition *config.ConditionRet) error {
+		if req.NodeRole != "ADMIN" {
+			condition.Add("user_id", "u1")
+		}
+		return nil
+	}
+	s.Load()
+
+	write := func(method string, role string, data model.Map) (model.Map, error) {
+		data["@role"] = role
+		ret, err := s.NewAction(ctx, method, model.Map{"tag": "FwDoc", "FwDoc": data}).Result()
+		if err != nil {
+			return nil, err
+		}
+		return gconv.Map(ret["FwDoc"]), nil
+	}
+	denied := func(err error) bool {
+		e, ok := err.(consts.Err)
+		return ok && e.Code() == 403
+	}
+
+	// condition 加入的 user_id 不在可写入的字段中, 但不是前端传入的
+	ret, err := write(http.MethodPost, "OWNER", model.Map{"title": "a"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	id := ret[consts.Id]
+
+	if _, err = write(http.MethodPost, "OWNER", model.Map{"title": "a", "secret": "x"}); !denied(err) {
+		t.Fatal(err)
+	}
+	if _, err = write(http.MethodPut, "OWNER", model.Map{"id": id, "title": "b"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = write(http.MethodPut, "OWNER", model.Map{"id": id, "secret": "x"}); !denied(err) {
+		t.Fatal(err)
+	}
+
+	// 未配置的角色使用继承的角色的字段
+	if _, err = write(http.MethodPost, "SUPPORT", model.Map{"title": "c"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = write(http.MethodPut, "SUPPORT", model.Map{"id": id, "secret": "x"}); !denied(err) {
+		t.Fatal(err)
+	}
+
+	if _, err = write(http.MethodPut, "ADMIN", model.Map{"id": id, "secret": "x"}); err != nil {
+		t.Fatal(err)
+	}
+}
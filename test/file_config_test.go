@@ -0,0 +1,159 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/glennliao/apijson-go"
+	fileConfig "github.com/glennliao/apijson-go/drivers/file/config"
+	"github.com/glennliao/apijson-go/model"
+	"github.com/gogf/gf/v2/os/gctx"
+)
+
+func writeConfigFiles(t *testing.T, dir string, files map[string]string) {
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestFileConfig(t *testing.T) {
+	dir := t.TempDir()
+	writeConfigFiles(t, dir, map[string]string{
+		"access/User.yaml": `
+name: user
+get: [UNKNOWN]
+rowKey: id
+fieldsGet:
+  default:
+    out: {id: "", username: ""}
+    maxCount: 10
+rules:
+  - when: "hour() < 0"
+    deny: true
+`,
+		"access/Todo.json": "{\n\t\"name\": \"todo\",\n\t\"get\": [\"UNKNOWN\"],\n\t\"post\": [\"UNKNOWN\"],\n\t\"rowKey\": \"id\"\n}",
+		"access/todo2.toml": `
+name = "todo"
+alias = "Todo2"
+get = ["UNKNOWN"]
+`,
+		"request/Todo.yaml": `
+version: 1
+methods:
+  POST:
+    structure:
+      Todo: {MUST: [content], REFUSE: [id]}
+    transaction: true
+`,
+		"request/Todo.v2.yaml": `
+tag: Todo
+version: 2
+methods:
+  POST: {}
+`,
+	})
+
+	accessList, err := fileConfig.LoadAccessList(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(accessList) != 3 {
+		t.Fatal(accessList)
+	}
+	for _, access := range accessList {
+		if access.Alias == "User" && (*access.FieldsGet["default"].MaxCount != 10 || len(access.Rules) != 1 || !access.Rules[0].Deny) {
+			t.Fatal("access decode", access)
+		}
+	}
+
+	requestList, err := fileConfig.LoadRequestList(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(requestList) != 2 || requestList[0].Version != "1" || requestList[1].Version != "2" {
+		t.Fatal(requestList)
+	}
+	if must := requestList[0].Structure["Todo"].Must; len(must) != 1 || must[0] != "content" || !*requestList[0].Transaction {
+		t.Fatal("request decode", requestList[0])
+	}
+
+	s := apijson.New()
+	s.UsePlugin(&fileConfig.Plugin{Dir: dir})
+	s.Load()
+	_, err = s.NewQuery(gctx.New(), model.Map{"User": model.Map{}}).Result()
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestFileConfigErr(t *testing.T) {
+	for name, c := range map[string]struct {
+		files  map[string]string
+		expect string
+	}{
+		"unknown field": {map[string]string{"access/User.yaml": "name: user\nrowKeys: id\n"}, "User.yaml:2: rowKeys: unknown field"},
+		"type":          {map[string]string{"access/User.yaml": "name: user\nget: UNKNOWN\n"}, "User.yaml:2: get: expect list"},
+		"nested":        {map[string]string{"access/User.json": "{\n\t\"name\": \"user\",\n\t\"fieldsGet\": {\n\t\t\"default\": {\"maxCount\": \"10\"}\n\t}\n}"}, "User.json:4: fieldsGet.default.maxCount: expect int"},
+		"json syntax":   {map[string]string{"access/User.json": "{\n\t\"name\": \"user\",\n}"}, "User.json:2:"},
+		"json trailing": {map[string]string{"access/User.json": "{\"name\": \"user\"}\n x"}, "User.json:2:2: invalid content after top-level value"},
+		"json extra":    {map[string]string{"access/User.json": "{\"name\": \"user\"} {}"}, "User.json:1:18: invalid content after top-level value"},
+		"toml syntax":   {map[string]string{"access/User.toml": "name = \"user\"\nget = [\n"}, "User.toml:2:8: unexpected EOF; expected value"},
+		"name":          {map[string]string{"access/User.yaml": "alias: User\n"}, "User.yaml:1: name required"},
+		"alias":         {map[string]string{"access/User.yaml": "name: user\n", "access/user2.yaml": "name: user\nalias: User\n"}, "duplicate alias User"},
+		"rules":         {map[string]string{"access/User.yaml": "name: user\n\nrules:\n  - when: \"principal. == 1\"\n    deny: true\n"}, "User.yaml:3: Rules: rule 0"},
+		"method":        {map[string]string{"request/User.yaml": "methods:\n  GET: {}\n"}, "User.yaml:2: methods: unknown method GET"},
+		"exec queue":    {map[string]string{"request/User.yaml": "methods:\n  POST:\n    execQueue:\n      - User\n      - Todo\n"}, "User.yaml:5: methods.POST.execQueue: Todo not in structure"},
+	} {
+		dir := t.TempDir()
+		writeConfigFiles(t, dir, c.files)
+
+		_, err := fileConfig.LoadAccessList(dir)
+		if err == nil {
+			_, err = fileConfig.LoadRequestList(dir)
+		}
+		if err == nil || !strings.Contains(err.Error(), c.expect) {
+			t.Fatal(name, err)
+		}
+	}
+}
+
+func TestFileConfigWatch(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	writeConfigFiles(t, dir, map[string]string{"access/User.yaml": "name: user\nget: [ADMIN]\n"})
+
+	var reloadErr error
+	s := apijson.New()
+	s.UsePlugin(&fileConfig.Plugin{Dir: dir, Watch: true, OnErr: func(err error) { reloadErr = err }})
+	s.Load()
+	if err := s.Start(ctx); err != nil {
+		t.Fatal(err)
+	}
+	defer s.Stop(ctx)
+
+	_, err := s.NewQuery(ctx, model.Map{"User": model.Map{}}).Result()
+	if err == nil {
+		t.Fatal("no access")
+	}
+
+	loaded := s.Config().Snapshot()
+	writeConfigFiles(t, dir, map[string]string{"access/User.yaml": "name: user\nget: [UNKNOWN]\n"})
+	for i := 0; i < 50 && s.Config().Snapshot() == loaded; i++ {
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	_, err = s.NewQuery(ctx, model.Map{"User": model.Map{}}).Result()
+	if err != nil || reloadErr != nil {
+		t.Fatal("not reloaded", err, reloadErr)
+	}
+}
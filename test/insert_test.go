@@ -0,0 +1,94 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/glennliao/apijson-go"
+	"github.com/glennliao/apijson-go/config"
+	"github.com/glennliao/apijson-go/consts"
+	"github.com/glennliao/apijson-go/model"
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/os/gctx"
+	"github.com/gogf/gf/v2/util/gconv"
+)
+
+// memoApi memo 表, 可 post/put Memo[]
+func memoApi(t *testing.T) *apijson.ApiJson {
+	ctx := gctx.New()
+
+	config.RegAccessListProvider("memo", func(ctx context.Context) []config.AccessConfig {
+		return []config.AccessConfig{{Name: "memo", Alias: "Memo", Post: []string{"UNKNOWN"}, Put: []string{"UNKNOWN"}, RowKey: "id"}}
+	})
+	config.RegRequestListProvider("memo", func(ctx context.Context) []config.RequestConfig {
+		var list []config.RequestConfig
+		for _, method := range []string{http.MethodPost, http.MethodPut} {
+			list = append(list, config.RequestConfig{Tag: "Memo[]", Method: method, Version: "1", ExecQueue: []string{"Memo[]"}, Structure: map[string]*config.Structure{"Memo[]": {}}})
+		}
+		return list
+	})
+
+	for _, sql := range []string{
+		"CREATE TABLE IF NOT EXISTS memo (id integer primary key autoincrement, title text, status text not null default 'new')",
+		"DELETE FROM memo",
+	} {
+		if _, err := g.DB().Exec(ctx, sql); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	s := apijson.New()
+	s.Config().AccessListProvider = "memo"
+	s.Config().RequestListProvider = "memo"
+	s.Load()
+	return s
+}
+
+func TestInsertDifferentFields(t *testing.T) {
+	ctx := gctx.New()
+	s := memoApi(t)
+
+	// 各行的字段不同, 未传入的字段使用默认值
+	ret, err := s.NewAction(ctx, http.MethodPost, model.Map{"tag": "Memo[]", "Memo[]": []model.Map{{"title": "a"}, {"status": "done"}}}).Result()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ids := gconv.Int64s(gconv.Map(ret["Memo[]"])[consts.IdList])
+	if len(ids) != 2 {
+		t.Fatal(ret)
+	}
+
+	rows, err := g.DB().GetAll(ctx, "SELECT id, title, status FROM memo ORDER BY id")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 2 || rows[0]["id"].Int64() != ids[0] || rows[0]["status"].String() != "new" ||
+		rows[1]["id"].Int64() != ids[1] || rows[1]["status"].String() != "done" || !rows[1]["title"].IsNil() {
+		t.Fatal(rows)
+	}
+}
+
+func TestBatchUpdateCount(t *testing.T) {
+	ctx := gctx.New()
+	s := memoApi(t)
+
+	ret, err := s.NewAction(ctx, http.MethodPost, model.Map{"tag": "Memo[]", "Memo[]": []model.Map{{"title": "a"}, {"title": "b"}}}).Result()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ids := gconv.Int64s(gconv.Map(ret["Memo[]"])[consts.IdList])
+
+	// 值未变化的行同样计为命中
+	ret, err = s.NewAction(ctx, http.MethodPut, model.Map{"tag": "Memo[]", "Memo[]": []model.Map{
+		{"id": ids[0], "status": "new"}, {"id": ids[1], "status": "done"}, {"id": ids[1] + 100, "status": "done"},
+	}}).Result()
+	if err != nil {
+		t.Fatal(err)
+	}
+	result := gconv.Map(ret["Memo[]"])
+	if counts := gconv.Int64s(result[consts.CountList]); gconv.Int(result["count"]) != 2 || len(counts) != 3 ||
+		counts[0] != 1 || counts[1] != 1 || counts[2] != 0 {
+		t.Fatal(result)
+	}
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/glennliao/apijson-go"
+	"github.com/glennliao/apijson-go/cli"
+	"github.com/glennliao/apijson-go/config"
+	"github.com/glennliao/apijson-go/consts"
+	fileConfig "github.com/glennliao/apijson-go/drivers/file/config"
+	"github.com/glennliao/apijson-go/model"
+)
+
+func TestLint(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	writeConfigFiles(t, dir, map[string]string{
+		"access/User.yaml": `
+name: user
+rowKey: id
+get: [UNKNOWN, GUEST, SUPPORT]
+fieldsGet:
+  default:
+    out: {id: "", password: ""}
+softDeleteColumn: deleted_at
+rowKeyGen: seq
+`,
+		"access/Todo.yaml": "name: t_todo\n",
+		"request/Todo.yaml": `
+methods:
+  PUT:
+    structure:
+      Todo:
+        UPDATE: {"updatedBy()": "currentUser()"}
+    execQueue: [Todo]
+    executor: {Todo: mq}
+`,
+		"schema.json": `[{"Name": "user", "Columns": [{"Name": "id"}, {"Name": "username"}, {"Name": "deleted_at"}]}]`,
+	})
+
+	s := apijson.New()
+	s.UsePlugin(&fileConfig.Plugin{Dir: dir})
+	s.Config().Access.RoleInherit("SUPPORT", consts.LOGIN)
+	s.Config().Functions.Bind("other", config.Func{Handler: func(ctx context.Context, param model.Map) (any, error) { return nil, nil }})
+
+	var out bytes.Buffer
+	cli.Stdout = &out
+	defer func() { cli.Stdout = os.Stdout }()
+	code := cli.Run(ctx, s, []string{"lint", "-schema", filepath.Join(dir, "schema.json")})
+	if code != 1 {
+		t.Fatal("exit code", code, out.String())
+	}
+
+	for _, expect := range []string{
+		"User.yaml: Get: unknown role GUEST",
+		"User.yaml: FieldsGet.default.Out: column password not found in user",
+		"User.yaml: RowKeyGen: rowKeyGen seq not registered",
+		"Todo.yaml: Name: table t_todo not found",
+		"Todo.yaml:3: Structure.Todo.UPDATE.updatedBy(): function currentUser not bound",
+		"Todo.yaml:3: Executor.Todo: action executor mq not registered",
+	} {
+		if !strings.Contains(out.String(), expect) {
+			t.Fatal(expect, "\n", out.String())
+		}
+	}
+	// 角色继承中的角色同样存在
+	if strings.Contains(out.String(), "deleted_at") || strings.Contains(out.String(), "ExecQueue") || strings.Contains(out.String(), "SUPPORT") {
+		t.Fatal(out.String())
+	}
+
+	problems, err := s.Lint(ctx, config.NewDbMeta([]config.Table{{Name: "user", Columns: []config.Column{{Name: "id"}, {Name: "password"}, {Name: "deleted_at"}}}, {Name: "t_todo"}}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(problems) != 4 {
+		t.Fatal(problems)
+	}
+}
+
+func TestLintPanic(t *testing.T) {
+	config.RegAccessListProvider("lintPanic", func(ctx context.Context) []config.AccessConfig {
+		panic("broken provider")
+	})
+
+	s := apijson.New()
+	s.Config().AccessListProvider = "lintPanic"
+	_, err := s.Lint(context.Background(), config.NewDbMeta(nil))
+	if err == nil || !strings.Contains(err.Error(), "lint config: broken provider") {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/glennliao/apijson-go"
+	"github.com/glennliao/apijson-go/cli"
+	"github.com/glennliao/apijson-go/config"
+	"github.com/glennliao/apijson-go/model"
+	"github.com/glennliao/apijson-go/openapi"
+	"github.com/gogf/gf/v2/os/gctx"
+)
+
+func TestOpenApi(t *testing.T) {
+	ctx := gctx.New()
+
+	s := apijson.New()
+	s.Config().RegAccessListProvider("instance", func(ctx context.Context) []config.AccessConfig {
+		return []config.AccessConfig{{
+			Name: "user", Alias: "User", Get: []string{"UNKNOWN"}, Post: []string{"UNKNOWN"},
+			FieldsGet: map[string]*config.FieldsGetValue{"default": {In: map[string][]string{"username": {"$"}}, Out: map[string]string{"id": "", "username": ""}}},
+			AutoFill:  map[string]*config.AutoFillValue{"created_at": {Post: "$now"}},
+		}, {Name: "todo", Alias: "Todo", Put: []string{"LOGIN"}}}
+	})
+	s.Config().RegRequestListProvider("instance", func(ctx context.Context) []config.RequestConfig {
+		return []config.RequestConfig{
+			{Tag: "User", Method: http.MethodPost, Version: "1", Structure: map[string]*config.Structure{"User": {Must: []string{"username"}, Refuse: []string{"id"}}}},
+			{Tag: "Todo", Method: http.MethodPut, Version: "1", Structure: map[string]*config.Structure{"Todo": {}}},
+		}
+	})
+	s.Config().AccessListProvider = "instance"
+	s.Config().RequestListProvider = "instance"
+	s.Load()
+
+	doc := openapi.Document(ctx, s, openapi.Options{Prefix: "/api"})
+	schemas := doc["components"].(model.Map)["schemas"].(model.Map)
+
+	row := schemas["User"].(model.Map)["properties"].(model.Map)
+	if len(row) != 2 || row["id"].(model.Map)["type"] != "integer" || row["username"] == nil {
+		t.Fatal("row", row)
+	}
+	query := schemas["User.Query"].(model.Map)["properties"].(model.Map)
+	if query["username$"] == nil || query["username"] != nil || query["id"] != nil {
+		t.Fatal("query", query)
+	}
+
+	body := schemas["User.POST.v1"].(model.Map)["properties"].(model.Map)["User"].(model.Map)
+	fields := body["properties"].(model.Map)
+	if fields["id"] != nil || fields["createdAt"] != nil || fields["password"] == nil {
+		t.Fatal("post", fields)
+	}
+	if required := body["required"].([]string); len(required) != 1 || required[0] != "username" {
+		t.Fatal("required", required)
+	}
+
+	paths := doc["paths"].(model.Map)
+	if paths["/api/get"] == nil || paths["/api/post"] == nil || paths["/api/put"] != nil {
+		t.Fatal("paths", paths)
+	}
+
+	var out bytes.Buffer
+	cli.Stdout = &out
+	defer func() { cli.Stdout = os.Stdout }()
+	if code := cli.Run(ctx, s, []string{"openapi", "-schemas", "-role", "LOGIN"}); code != 0 {
+		t.Fatal("exit code", code)
+	}
+	requests := map[string]model.Map{}
+	if err := json.Unmarshal(out.Bytes(), &requests); err != nil {
+		t.Fatal(err)
+	}
+	if requests["Todo@PUT@1"] == nil || requests["User@POST@1"] == nil {
+		t.Fatal(out.String())
+	}
+
+	ts := openapi.TypeScript(ctx, s, openapi.Options{}, []string{"UNKNOWN", "LOGIN"})
+	for _, expect := range []string{
+		"export namespace UNKNOWN {",
+		"    id: number;\n",
+		"    username: string | null;\n",
+		"export type PostReq = User_POST_v1;",
+		"    username$?: string;",
+		"  post: (req: PostReq) =>",
+		"export namespace LOGIN {",
+		"export type PutReq = Todo_PUT_v1;",
+	} {
+		if !strings.Contains(ts, expect) {
+			t.Fatal(expect, "\n", ts)
+		}
+	}
+}
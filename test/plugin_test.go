@@ -0,0 +1,119 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/glennliao/apijson-go"
+	"github.com/glennliao/apijson-go/config"
+	"github.com/glennliao/apijson-go/model"
+)
+
+type testPlugin struct {
+	name     string
+	depends  []string
+	events   *[]string
+	startErr error
+}
+
+func (p *testPlugin) Name() string      { return p.name }
+func (p *testPlugin) Depends() []string { return p.depends }
+
+func (p *testPlugin) Install(ctx context.Context, a *apijson.ApiJson) {
+	*p.events = append(*p.events, "install "+p.name)
+	a.Contribute(&apijson.Contributions{
+		Functions: map[string]config.Func{
+			p.name: {Handler: func(ctx context.Context, param model.Map) (res any, err error) {
+				return p.name, nil
+			}},
+		},
+	})
+}
+
+func (p *testPlugin) Start(ctx context.Context) error {
+	*p.events = append(*p.events, "start "+p.name)
+	return p.startErr
+}
+
+func (p *testPlugin) Stop(ctx context.Context) error {
+	*p.events = append(*p.events, "stop "+p.name)
+	return nil
+}
+
+func TestPluginLifecycle(t *testing.T) {
+	ctx := context.Background()
+
+	var events []string
+	s := apijson.New()
+	s.Config().AccessListProvider = "plugin"
+	s.Config().RequestListProvider = "plugin"
+	s.UsePlugin(
+		&testPlugin{name: "cache", depends: []string{"audit"}, events: &events},
+		&testPlugin{name: "audit", events: &events},
+		&testPlugin{name: "tenant", events: &events},
+	)
+	s.Load()
+
+	if err := s.Start(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Stop(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	expect := []string{
+		"install audit", "install cache", "install tenant",
+		"start audit", "start cache", "start tenant",
+		"stop tenant", "stop cache", "stop audit",
+	}
+	if !reflect.DeepEqual(events, expect) {
+		t.Fatal(events)
+	}
+
+	ret, err := s.Config().Functions.Call(ctx, "cache", model.Map{})
+	if err != nil || ret != "cache" {
+		t.Fatal("function not contributed", ret, err)
+	}
+}
+
+func TestPluginStartErr(t *testing.T) {
+	ctx := context.Background()
+
+	var events []string
+	s := apijson.New()
+	s.Config().AccessListProvider = "plugin"
+	s.Config().RequestListProvider = "plugin"
+	s.UsePlugin(
+		&testPlugin{name: "audit", events: &events},
+		&testPlugin{name: "cache", events: &events, startErr: errors.New("redis down")},
+	)
+	s.Load()
+
+	if err := s.Start(ctx); err == nil {
+		t.Fatal("start error ignored")
+	}
+
+	expect := []string{"install audit", "install cache", "start audit", "start cache", "stop audit"}
+	if !reflect.DeepEqual(events, expect) {
+		t.Fatal(events)
+	}
+}
+
+func TestPluginDependsErr(t *testing.T) {
+	for _, plugins := range [][]apijson.Plugin{
+		{&testPlugin{name: "cache", depends: []string{"redis"}, events: new([]string)}},
+		{&testPlugin{name: "a", depends: []string{"b"}, events: new([]string)}, &testPlugin{name: "b", depends: []string{"a"}, events: new([]string)}},
+		{&testPlugin{name: "a", events: new([]string)}, &testPlugin{name: "a", events: new([]string)}},
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Fatal("invalid plugins loaded")
+				}
+			}()
+			apijson.New().UsePlugin(plugins...).Load()
+		}()
+	}
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/glennliao/apijson-go"
+	"github.com/glennliao/apijson-go/action"
+	"github.com/glennliao/apijson-go/config"
+	gfExecutor "github.com/glennliao/apijson-go/drivers/goframe/executor"
+	"github.com/glennliao/apijson-go/model"
+	"github.com/glennliao/apijson-go/query"
+	"github.com/gogf/gf/v2/os/gctx"
+)
+
+func newRegistryApiJson(hooked *[]string, name string) *apijson.ApiJson {
+	tx := true
+	s := apijson.New()
+
+	s.Config().RegAccessListProvider("instance", func(ctx context.Context) []config.AccessConfig {
+		roles := []string{"UNKNOWN"}
+		return []config.AccessConfig{{Name: "todo", Alias: "Todo", Get: roles, Post: roles, Delete: roles, RowKey: "id",
+			FieldsGet: map[string]*config.FieldsGetValue{"default": {In: map[string][]string{"id": {"*"}}}}}}
+	})
+	s.Config().RegRequestListProvider("instance", func(ctx context.Context) []config.RequestConfig {
+		var list []config.RequestConfig
+		for _, method := range []string{http.MethodPost, http.MethodDelete} {
+			list = append(list, config.RequestConfig{Tag: "Todo", Method: method, Version: "1", Transaction: &tx,
+				Structure: map[string]*config.Structure{"Todo": {}}})
+		}
+		return list
+	})
+	s.Config().AccessListProvider = "instance"
+	s.Config().RequestListProvider = "instance"
+
+	s.ActionRegistry().RegHook(action.Hook{
+		For: []string{"Todo"},
+		BeforeExecutorDo: func(ctx context.Context, n *action.Node, method string) error {
+			*hooked = append(*hooked, name)
+			return nil
+		},
+	})
+
+	s.Load()
+	return s
+}
+
+func TestRegistryPerInstance(t *testing.T) {
+	ctx := gctx.New()
+
+	var hooked []string
+	admin := newRegistryApiJson(&hooked, "admin")
+	public := newRegistryApiJson(&hooked, "public")
+
+	queried := 0
+	admin.QueryExecutors().RegExecutor("default", func(ctx context.Context, config *config.ExecutorConfig) (query.QueryExecutor, error) {
+		queried++
+		return gfExecutor.New(ctx, config)
+	})
+
+	ret, err := public.NewAction(ctx, http.MethodPost, model.Map{"tag": "Todo", "Todo": model.Map{"content": "public"}}).Result()
+	if err != nil {
+		t.Fatal(err)
+	}
+	id := ret["Todo"].(model.Map)["id"]
+
+	if len(hooked) != 1 || hooked[0] != "public" {
+		t.Fatal("hook of other instance called", hooked)
+	}
+
+	_, err = public.NewQuery(ctx, model.Map{"Todo": model.Map{"id": id}}).Result()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if queried != 0 {
+		t.Fatal("executor of other instance used")
+	}
+
+	_, err = admin.NewQuery(ctx, model.Map{"Todo": model.Map{"id": id}}).Result()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if queried != 1 {
+		t.Fatal("instance executor not used")
+	}
+
+	_, err = admin.NewAction(ctx, http.MethodDelete, model.Map{"tag": "Todo", "Todo": model.Map{"id": id}}).Result()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(hooked) != 2 || hooked[1] != "admin" {
+		t.Fatal("instance hook not called", hooked)
+	}
+}
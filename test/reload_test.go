@@ -0,0 +1,174 @@
+package main
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/glennliao/apijson-go"
+	"github.com/glennliao/apijson-go/config"
+	"github.com/glennliao/apijson-go/model"
+	"github.com/gogf/gf/v2/os/gctx"
+	"github.com/gogf/gf/v2/util/gconv"
+)
+
+func TestReload(t *testing.T) {
+	ctx := gctx.New()
+
+	var lock sync.Mutex
+	roles := []string{"UNKNOWN"}
+	rules := []*config.AccessRule(nil)
+
+	config.RegAccessListProvider("reload", func(ctx context.Context) []config.AccessConfig {
+		lock.Lock()
+		defer lock.Unlock()
+		return []config.AccessConfig{{Name: "user", Alias: "User", Get: roles, RowKey: "id", Rules: rules}}
+	})
+
+	s := apijson.New()
+	s.Config().AccessListProvider = "reload"
+	s.Config().RequestListProvider = "reload"
+	s.Load()
+
+	_, err := s.NewQuery(ctx, model.Map{"User": model.Map{}}).Result()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 进行中的请求使用开始时的快照
+	inflight := s.Config().Snapshot()
+
+	lock.Lock()
+	roles = []string{"ADMIN"}
+	lock.Unlock()
+
+	err = s.Reload(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if s.Config().Snapshot() == inflight {
+		t.Fatal("snapshot not swapped")
+	}
+
+	access, err := inflight.QueryConfig().GetAccessConfig("User", false)
+	if err != nil || len(access.Get) != 1 || access.Get[0] != "UNKNOWN" {
+		t.Fatal("inflight snapshot changed", access, err)
+	}
+
+	_, err = s.NewQuery(ctx, model.Map{"User": model.Map{}}).Result()
+	if err == nil {
+		t.Fatal("new snapshot not used")
+	}
+
+	// 加载失败时保留原快照
+	loaded := s.Config().Snapshot()
+
+	lock.Lock()
+	rules = []*config.AccessRule{{When: "principal. == 1", Deny: true}}
+	lock.Unlock()
+
+	err = s.Reload(ctx)
+	if err == nil {
+		t.Fatal("reload with invalid rule")
+	}
+	if s.Config().Snapshot() != loaded {
+		t.Fatal("snapshot swapped after failed reload")
+	}
+}
+
+func TestReloadConcurrent(t *testing.T) {
+	ctx := gctx.New()
+
+	config.RegAccessListProvider("reload_concurrent", func(ctx context.Context) []config.AccessConfig {
+		return []config.AccessConfig{{Name: "user", Alias: "User", Get: []string{"UNKNOWN"}, RowKey: "id"}}
+	})
+
+	s := apijson.New()
+	s.Config().AccessListProvider = "reload_concurrent"
+	s.Config().RequestListProvider = "reload_concurrent"
+	s.Load()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 20; j++ {
+				if err := s.Reload(ctx); err != nil {
+					t.Error(err)
+				}
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 20; j++ {
+				if _, err := s.NewQuery(ctx, model.Map{"User": model.Map{}}).Result(); err != nil {
+					t.Error(err)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestHookAfterLoad(t *testing.T) {
+	ctx := gctx.New()
+
+	config.RegAccessListProvider("hook", func(ctx context.Context) []config.AccessConfig {
+		return []config.AccessConfig{{Name: "user", Alias: "User", Get: []string{"UNKNOWN"}, RowKey: "id"}}
+	})
+
+	s := apijson.New()
+	s.Config().AccessListProvider = "hook"
+
+	_, err := s.NewQuery(ctx, model.Map{"User": model.Map{}}).Result()
+	if err == nil {
+		t.Fatal("query before load")
+	}
+	_, err = s.NewAction(ctx, "POST", model.Map{"tag": "User", "User": model.Map{}}).Result()
+	if err == nil {
+		t.Fatal("action before load")
+	}
+
+	s.Load()
+
+	// Load 之后设置的函数同样生效
+	s.Config().Access.ConditionFunc = func(ctx context.Context, req config.ConditionReq, condition *config.ConditionRet) error {
+		condition.AddRaw("id", "= -1")
+		return nil
+	}
+	ret, err := s.NewQuery(ctx, model.Map{"User": model.Map{}}).Result()
+	if err != nil || len(gconv.Map(ret["User"])) > 0 {
+		t.Fatal("condition func not applied", ret, err)
+	}
+}
+
+func TestReloadDbMeta(t *testing.T) {
+	ctx := gctx.New()
+
+	c := config.New()
+	c.AccessListProvider, c.RequestListProvider, c.DbMetaProvider = "", "", ""
+	c.DbMeta = config.NewDbMeta([]config.Table{{Name: "a"}})
+	if err := c.Reload(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	// 未设置 DbMetaProvider 时, 替换的 DbMeta 在 Reload 后生效
+	c.DbMeta = config.NewDbMeta([]config.Table{{Name: "b"}})
+	if err := c.Reload(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if c.Snapshot().DbMeta().GetTable("b") == nil || c.Snapshot().DbMeta().GetTable("a") != nil {
+		t.Fatal(c.Snapshot().DbMeta().GetTableNameList())
+	}
+
+	// 未设置 DbMeta 时沿用上次加载的
+	c.DbMeta = nil
+	if err := c.Reload(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if c.Snapshot().DbMeta().GetTable("b") == nil {
+		t.Fatal(c.Snapshot().DbMeta())
+	}
+}
@@ -0,0 +1,170 @@
+package main
+
+import (
+	"context"
+	"regexp"
+	"sync"
+	"testing"
+
+	"github.com/glennliao/apijson-go/config"
+	"github.com/glennliao/apijson-go/consts"
+	"github.com/gogf/gf/v2/util/gconv"
+)
+
+func genRowKey(t *testing.T, c *config.Config, name string) string {
+	ret, err := c.RowKeyGen(context.Background(), name, "User", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return gconv.String(ret[consts.RowKey])
+}
+
+// rowKeyGenConfig snowflake 节点号为 1, 并注册 order 短id
+func rowKeyGenConfig(t *testing.T) *config.Config {
+	c := config.New()
+	c.SnowflakeNode = 1
+	order, err := config.ShortIdRowKeyGen("ord_", 12)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.RowKeyGenFunc("order", order)
+	return c
+}
+
+func TestRowKeyGenBuiltin(t *testing.T) {
+	c := rowKeyGenConfig(t)
+
+	formats := map[string]*regexp.Regexp{
+		config.RowKeyGenSnowflake: regexp.MustCompile(`^[1-9][0-9]{15,18}$`),
+		config.RowKeyGenUUID:      regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`),
+		config.RowKeyGenUUIDv7:    regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-7[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`),
+		config.RowKeyGenULID:      regexp.MustCompile(`^[0-7][0-9A-HJKMNP-TV-Z]{25}$`),
+		config.RowKeyGenKSUID:     regexp.MustCompile(`^[0-9A-Za-z]{27}$`),
+		"order":                   regexp.MustCompile(`^ord_[0-9A-Za-z]{12}$`),
+	}
+
+	for name, format := range formats {
+		id := genRowKey(t, c, name)
+		if !format.MatchString(id) {
+			t.Errorf("%s: unexpected format %q", name, id)
+		}
+	}
+}
+
+func TestRowKeyGenConcurrent(t *testing.T) {
+	c := rowKeyGenConfig(t)
+
+	names := []string{config.RowKeyGenSnowflake, config.RowKeyGenUUID, config.RowKeyGenUUIDv7, config.RowKeyGenULID, config.RowKeyGenKSUID, "order"}
+
+	const workers, each = 8, 2000
+
+	for _, name := range names {
+		var (
+			mu   sync.Mutex
+			seen = make(map[string]bool, workers*each)
+			wg   sync.WaitGroup
+		)
+		for w := 0; w < workers; w++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				ids := make([]string, 0, each)
+				for i := 0; i < each; i++ {
+					ids = append(ids, genRowKey(t, c, name))
+				}
+				mu.Lock()
+				defer mu.Unlock()
+				for _, id := range ids {
+					if seen[id] {
+						t.Errorf("%s: duplicate id %s", name, id)
+					}
+					seen[id] = true
+				}
+			}()
+		}
+		wg.Wait()
+	}
+}
+
+func TestRowKeyGenOrdered(t *testing.T) {
+	c := config.New()
+
+	for _, name := range []string{config.RowKeyGenUUIDv7, config.RowKeyGenULID} {
+		last := ""
+		for i := 0; i < 10000; i++ {
+			id := genRowKey(t, c, name)
+			if id <= last {
+				t.Fatalf("%s: %s not after %s", name, id, last)
+			}
+			last = id
+		}
+	}
+
+	s, err := config.NewSnowflake(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var last int64
+	for i := 0; i < 10000; i++ {
+		id, err := s.Next()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if id <= last {
+			t.Fatalf("snowflake: %d not after %d", id, last)
+		}
+		if node := id >> 12 & 1023; node != 1 {
+			t.Fatalf("snowflake: node %d", node)
+		}
+		last = id
+	}
+}
+
+func TestSnowflakeNode(t *testing.T) {
+	if _, err := config.SnowflakeRowKeyGen(1024); err == nil {
+		t.Error("node 1024 should be rejected")
+	}
+	if _, err := config.SnowflakeRowKeyGen(-1); err == nil {
+		t.Error("node -1 should be rejected")
+	}
+	if _, err := config.SnowflakeRowKeyGen(1023); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestRowKeyGenDefault(t *testing.T) {
+	c := config.New()
+
+	// snowflake 未设置节点号时返回错误
+	if _, err := c.RowKeyGen(context.Background(), config.RowKeyGenSnowflake, "User", nil); err == nil {
+		t.Error("snowflake without node")
+	}
+	c.SnowflakeNode = 3
+	if node := gconv.Int64(genRowKey(t, c, config.RowKeyGenSnowflake)) >> 12 & 1023; node != 3 {
+		t.Errorf("snowflake: node %d", node)
+	}
+
+	// shortid 按 access 设置前缀
+	c.ShortIdPrefix = map[string]string{"User": "usr_"}
+	c.ShortIdLength = 10
+	if id := genRowKey(t, c, config.RowKeyGenShortId); !regexp.MustCompile(`^usr_[0-9A-Za-z]{10}$`).MatchString(id) {
+		t.Error(id)
+	}
+	if id, err := c.RowKeyGen(context.Background(), config.RowKeyGenShortId, "Todo", nil); err != nil || len(gconv.String(id[consts.RowKey])) != 10 {
+		t.Error(id, err)
+	}
+}
+
+func TestShortIdLength(t *testing.T) {
+	for _, length := range []int{-1, 0, 7} {
+		if _, err := config.ShortIdRowKeyGen("ord_", length); err == nil {
+			t.Errorf("length %d should be rejected", length)
+		}
+		if _, err := config.NewShortId("ord_", length); err == nil {
+			t.Errorf("length %d should be rejected", length)
+		}
+	}
+	if id, err := config.NewShortId("", 8); err != nil || len(id) != 8 {
+		t.Error(id, err)
+	}
+}
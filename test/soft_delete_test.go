@@ -0,0 +1,131 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/glennliao/apijson-go"
+	"github.com/glennliao/apijson-go/config"
+	"github.com/glennliao/apijson-go/model"
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/os/gctx"
+	"github.com/gogf/gf/v2/util/gconv"
+)
+
+func TestSoftDeleteNoVerify(t *testing.T) {
+	ctx := gctx.New()
+	all := []string{"UNKNOWN"}
+
+	config.RegAccessListProvider("softDelete", func(ctx context.Context) []config.AccessConfig {
+		return []config.AccessConfig{{Name: "trash", Alias: "Trash", Get: all, Delete: all, RowKey: "id", SoftDeleteColumn: "removed_at"}}
+	})
+	config.RegRequestListProvider("softDelete", func(ctx context.Context) []config.RequestConfig {
+		return []config.RequestConfig{{Tag: "Trash", Method: http.MethodDelete, Version: "1", Structure: map[string]*config.Structure{"Trash": {}}}}
+	})
+
+	for _, sql := range []string{
+		"CREATE TABLE IF NOT EXISTS trash (id integer primary key, title text, removed_at datetime)",
+		"DELETE FROM trash",
+		"INSERT INTO trash (id, title, removed_at) VALUES (1, 'a', '2026-01-01 00:00:00')",
+	} {
+		if _, err := g.DB().Exec(ctx, sql); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	s := apijson.New()
+	s.Config().AccessListProvider = "softDelete"
+	s.Config().RequestListProvider = "softDelete"
+	s.Config().Access.NoVerify = true
+	s.Load()
+
+	// NoVerify 时 @deleted 不限制 ADMIN
+	ret, err := s.NewQuery(ctx, model.Map{"Trash": model.Map{"id": 1, "@deleted": 1}}).Result()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gconv.Map(ret["Trash"])["title"] != "a" {
+		t.Fatal(ret)
+	}
+
+	_, err = s.NewAction(ctx, http.MethodDelete, model.Map{"tag": "Trash", "Trash": model.Map{"id": 1, "@deleted": 1}}).Result()
+	if err != nil {
+		t.Fatal(err)
+	}
+	count, err := g.DB().GetCount(ctx, "SELECT * FROM trash")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 0 {
+		t.Fatal(count)
+	}
+}
+
+func TestSoftDeleteRole(t *testing.T) {
+	ctx := gctx.New()
+	roles := []string{"OWNER", "ADMIN"}
+
+	config.RegAccessListProvider("softDeleteRole", func(ctx context.Context) []config.AccessConfig {
+		return []config.AccessConfig{{Name: "trash_role", Alias: "TrashRole", Get: roles, Delete: roles, RowKey: "id", SoftDeleteColumn: "removed_at"}}
+	})
+	config.RegRequestListProvider("softDeleteRole", func(ctx context.Context) []config.RequestConfig {
+		return []config.RequestConfig{{Tag: "TrashRole", Method: http.MethodDelete, Version: "1", Structure: map[string]*config.Structure{"TrashRole": {}}}}
+	})
+
+	for _, sql := range []string{
+		"CREATE TABLE IF NOT EXISTS trash_role (id integer primary key, title text, removed_at datetime)",
+		"DELETE FROM trash_role",
+		"INSERT INTO trash_role (id, title, removed_at) VALUES (1, 'a', '2026-01-01 00:00:00'), (2, 'b', NULL)",
+	} {
+		if _, err := g.DB().Exec(ctx, sql); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	s := apijson.New()
+	s.Config().AccessListProvider = "softDeleteRole"
+	s.Config().RequestListProvider = "softDeleteRole"
+	s.Config().Access.DefaultRoleFunc = func(ctx context.Context, req config.RoleReq) (string, error) {
+		return req.NodeRole, nil
+	}
+	s.Load()
+
+	ids := func(role string, deleted any) ([]int, error) {
+		ret, err := s.NewQuery(ctx, model.Map{"TrashRole[]": model.Map{"@role": role, "@deleted": deleted}}).Result()
+		if err != nil {
+			return nil, err
+		}
+		var list []int
+		for _, item := range gconv.Maps(ret["TrashRole[]"]) {
+			list = append(list, gconv.Int(item["id"]))
+		}
+		return list, nil
+	}
+
+	// 非 ADMIN 不能使用 @deleted
+	if _, err := ids("OWNER", true); err == nil {
+		t.Fatal("owner get with @deleted")
+	}
+	_, err := s.NewAction(ctx, http.MethodDelete, model.Map{"tag": "TrashRole", "TrashRole": model.Map{"id": 2, "@role": "OWNER", "@deleted": 1}}).Result()
+	if err == nil {
+		t.Fatal("owner delete with @deleted")
+	}
+
+	// @deleted 为 false 时同未传入, 排除已删除的行
+	list, err := ids("OWNER", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 1 || list[0] != 2 {
+		t.Fatal(list)
+	}
+
+	list, err = ids("ADMIN", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 2 {
+		t.Fatal(list)
+	}
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/glennliao/apijson-go"
+	"github.com/glennliao/apijson-go/config"
+	"github.com/glennliao/apijson-go/model"
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/os/gctx"
+	"github.com/gogf/gf/v2/util/gconv"
+)
+
+type tenantKey struct{}
+
+func TestTenant(t *testing.T) {
+	ctx := gctx.New()
+	all := []string{"UNKNOWN"}
+
+	config.RegAccessListProvider("tenant", func(ctx context.Context) []config.AccessConfig {
+		return []config.AccessConfig{{Name: "note", Alias: "Note", Get: all, Post: all, Put: all, Delete: all, RowKey: "id", TenantColumn: "tenant_id",
+			FieldsGet: map[string]*config.FieldsGetValue{"default": {In: map[string][]string{"id": {"*"}, "tenant_id": {"*"}}}}}}
+	})
+	config.RegRequestListProvider("tenant", func(ctx context.Context) []config.RequestConfig {
+		var list []config.RequestConfig
+		for _, method := range []string{http.MethodPost, http.MethodPut, http.MethodDelete} {
+			list = append(list, config.RequestConfig{Tag: "Note", Method: method, Version: "1", Structure: map[string]*config.Structure{"Note": {}}})
+		}
+		return list
+	})
+
+	for _, sql := range []string{
+		"CREATE TABLE IF NOT EXISTS note (id integer primary key autoincrement, title text, tenant_id text)",
+		"DELETE FROM note",
+	} {
+		if _, err := g.DB().Exec(ctx, sql); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	s := apijson.New()
+	s.Config().AccessListProvider = "tenant"
+	s.Config().RequestListProvider = "tenant"
+	s.Config().Access.TenantIdFunc = func(ctx context.Context) (any, error) {
+		return ctx.Value(tenantKey{}), nil
+	}
+	s.Load()
+
+	t1 := context.WithValue(ctx, tenantKey{}, "t1")
+	t2 := context.WithValue(ctx, tenantKey{}, "t2")
+
+	ret, err := s.NewAction(t1, http.MethodPost, model.Map{"tag": "Note", "Note": model.Map{"title": "t1"}}).Result()
+	if err != nil {
+		t.Fatal(err)
+	}
+	id := gconv.Int64(ret["Note"].(model.Map)["id"])
+
+	title := func(ctx context.Context) any {
+		ret, err := s.NewQuery(ctx, model.Map{"Note": model.Map{"id": id}}).Result()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return gconv.Map(ret["Note"])["title"]
+	}
+
+	if title(t1) != "t1" {
+		t.Fatal("read own tenant")
+	}
+
+	// 其他租户不能读取、修改、删除
+	if title(t2) != nil {
+		t.Fatal("cross-tenant read")
+	}
+	_, _ = s.NewAction(t2, http.MethodPut, model.Map{"tag": "Note", "Note": model.Map{"id": id, "title": "t2"}}).Result()
+	_, _ = s.NewAction(t2, http.MethodDelete, model.Map{"tag": "Note", "Note": model.Map{"id": id}}).Result()
+	if title(t1) != "t1" {
+		t.Fatal("cross-tenant write")
+	}
+
+	// 请求中不能设置或筛选租户字段
+	_, err = s.NewAction(t2, http.MethodPost, model.Map{"tag": "Note", "Note": model.Map{"title": "x", "tenantId": "t1"}}).Result()
+	if err == nil {
+		t.Fatal("post with tenant column")
+	}
+	_, err = s.NewQuery(t2, model.Map{"Note[]": model.Map{"tenantId{}": []string{"t1"}}}).Result()
+	if err == nil {
+		t.Fatal("filter by tenant column")
+	}
+
+	// 未获取到租户id时拒绝访问
+	_, err = s.NewQuery(ctx, model.Map{"Note": model.Map{"id": id}}).Result()
+	if err == nil {
+		t.Fatal("no tenant")
+	}
+}
@@ -0,0 +1,28 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/glennliao/apijson-go/model"
+	"github.com/gogf/gf/v2/os/gctx"
+	"github.com/gogf/gf/v2/util/gconv"
+)
+
+// 引用节点与 [] 的解析、查询顺序不固定, 多次执行以覆盖不同的顺序
+func TestListTotal(t *testing.T) {
+	ctx := gctx.New()
+	for i := 0; i < 20; i++ {
+		for _, req := range []model.Map{
+			{"[]": model.Map{"User": model.Map{"@column": "id"}, "count": 1}, "total@": "[]/total"},
+			{"[]": model.Map{"User": model.Map{"@column": "id"}, "count": 1, "query": 2}, "total@": "[]/total"},
+			{"User[]": model.Map{"@column": "id", "count": 1}, "total@": "User[]/total"},
+		} {
+			q := a.NewQuery(ctx, req)
+			q.NoAccessVerify = true
+			ret, err := q.Result()
+			if err != nil || gconv.Int64(ret["total"]) < 2 {
+				t.Fatal(req, ret, err)
+			}
+		}
+	}
+}
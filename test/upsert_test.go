@@ -0,0 +1,200 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/glennliao/apijson-go"
+	"github.com/glennliao/apijson-go/config"
+	"github.com/glennliao/apijson-go/consts"
+	"github.com/glennliao/apijson-go/model"
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/os/gctx"
+	"github.com/gogf/gf/v2/util/gconv"
+)
+
+func TestUpsert(t *testing.T) {
+	ctx := gctx.New()
+
+	config.RegAccessListProvider("upsert", func(ctx context.Context) []config.AccessConfig {
+		return []config.AccessConfig{{Name: "kv", Alias: "Kv", Post: []string{"OWNER"}, Put: []string{"ADMIN"}, RowKey: "k"}}
+	})
+	config.RegRequestListProvider("upsert", func(ctx context.Context) []config.RequestConfig {
+		return []config.RequestConfig{{Tag: "Kv[]", Method: consts.MethodUpsert, Version: "1", ExecQueue: []string{"Kv[]"}, Structure: map[string]*config.Structure{"Kv[]": {}}}}
+	})
+
+	for _, sql := range []string{
+		"CREATE TABLE IF NOT EXISTS kv (k text primary key, v text, note text)",
+		"DELETE FROM kv",
+	} {
+		if _, err := g.DB().Exec(ctx, sql); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	s := apijson.New()
+	s.Config().AccessListProvider = "upsert"
+	s.Config().RequestListProvider = "upsert"
+	s.Config().Access.RoleInherit("SUPER", "OWNER", "ADMIN")
+	s.Config().Access.DefaultRoleFunc = func(ctx context.Context, req config.RoleReq) (string, error) {
+		return req.NodeRole, nil
+	}
+	s.Load()
+
+	upsert := func(role string, rows ...model.Map) (model.Map, error) {
+		for _, row := range rows {
+			row["@role"] = role
+		}
+		ret, err := s.NewAction(ctx, consts.MethodUpsert, model.Map{"tag": "Kv[]", "Kv[]": rows}).Result()
+		if err != nil {
+			return nil, err
+		}
+		return gconv.Map(ret["Kv[]"]), nil
+	}
+
+	// 需同时有 post 与 put 的权限, 包括继承的角色
+	if _, err := upsert("OWNER", model.Map{"k": "a", "v": "1"}); err == nil {
+		t.Fatal("OWNER without put")
+	}
+
+	ret, err := upsert("SUPER", model.Map{"k": "a", "v": "1"}, model.Map{"k": "b", "v": "2"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gconv.Int(ret["count"]) != 2 || gconv.Int(ret["inserted"]) != 2 {
+		t.Fatal(ret)
+	}
+
+	ret, err = upsert("SUPER", model.Map{"k": "a", "v": "3"}, model.Map{"k": "c", "v": "4"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gconv.Int(ret["count"]) != 2 || gconv.Int(ret["inserted"]) != 1 || gconv.Int(ret["updated"]) != 1 {
+		t.Fatal(ret)
+	}
+	if inserted, _ := ret[consts.InsertedList].([]bool); len(inserted) != 2 || inserted[0] || !inserted[1] {
+		t.Fatal(ret)
+	}
+
+	// 各行的字段需相同
+	if _, err = upsert("SUPER", model.Map{"k": "a", "v": "5"}, model.Map{"k": "d", "note": "x"}); err == nil {
+		t.Fatal("rows with different fields")
+	}
+}
+
+type upsertUserKey struct{}
+
+func TestUpsertCondition(t *testing.T) {
+	ctx := gctx.New()
+	roles := []string{"OWNER", "ADMIN"}
+
+	config.RegAccessListProvider("upsertCondition", func(ctx context.Context) []config.AccessConfig {
+		return []config.AccessConfig{{Name: "owned_kv", Alias: "OwnedKv", Post: roles, Put: roles, RowKey: "k"}}
+	})
+	config.RegRequestListProvider("upsertCondition", func(ctx context.Context) []config.RequestConfig {
+		return []config.RequestConfig{{Tag: "OwnedKv", Method: consts.MethodUpsert, Version: "1", Structure: map[string]*config.Structure{"OwnedKv": {}}}}
+	})
+
+	for _, sql := range []string{
+		"CREATE TABLE IF NOT EXISTS owned_kv (k text primary key, v text, user_id text)",
+		"DELETE FROM owned_kv",
+		"INSERT INTO owned_kv (k, v, user_id) VALUES ('a', '1', 'u1')",
+	} {
+		if _, err := g.DB().Exec(ctx, sql); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	s := apijson.New()
+	s.Config().AccessListProvider = "upsertCondition"
+	s.Config().RequestListProvider = "upsertCondition"
+	s.Config().Access.DefaultRoleFunc = func(ctx context.Context, req config.RoleReq) (string, error) {
+		return req.NodeRole, nil
+	}
+	s.Config().Access.ConditionFunc = func(ctx context.Context, req config.ConditionReq, condition *config.ConditionRet) error {
+		if req.NodeRole == "OWNER" {
+			condition.Add("user_id", ctx.Value(upsertUserKey{}))
+		}
+		return nil
+	}
+	s.Load()
+
+	u2 := context.WithValue(ctx, upsertUserKey{}, "u2")
+
+	// 有 condition 限制时不能 upsert, 否则冲突时会修改其他用户的行
+	_, err := s.NewAction(u2, consts.MethodUpsert, model.Map{"tag": "OwnedKv", "OwnedKv": model.Map{"k": "a", "v": "2", "@role": "OWNER"}}).Result()
+	if err == nil {
+		t.Fatal("upsert with condition")
+	}
+	row, err := g.DB().GetOne(ctx, "SELECT v, user_id FROM owned_kv WHERE k = 'a'")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if row["v"].String() != "1" || row["user_id"].String() != "u1" {
+		t.Fatal(row)
+	}
+
+	_, err = s.NewAction(u2, consts.MethodUpsert, model.Map{"tag": "OwnedKv", "OwnedKv": model.Map{"k": "a", "v": "3", "@role": "ADMIN"}}).Result()
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestUpsertTenant(t *testing.T) {
+	ctx := gctx.New()
+	all := []string{"UNKNOWN"}
+
+	config.RegAccessListProvider("upsertTenant", func(ctx context.Context) []config.AccessConfig {
+		return []config.AccessConfig{{Name: "tenant_kv", Alias: "TenantKv", Post: all, Put: all, RowKey: "k", TenantColumn: "tenant_id"}}
+	})
+	config.RegRequestListProvider("upsertTenant", func(ctx context.Context) []config.RequestConfig {
+		return []config.RequestConfig{
+			{Tag: "TenantKv", Method: consts.MethodUpsert, Version: "1", Structure: map[string]*config.Structure{"TenantKv": {}}},
+			{Tag: "TenantKv", Method: consts.MethodUpsert, Version: "2", Structure: map[string]*config.Structure{"TenantKv": {Unique: []string{"tenantId", "k"}}}},
+		}
+	})
+
+	for _, sql := range []string{
+		"CREATE TABLE IF NOT EXISTS tenant_kv (tenant_id text, k text, v text, PRIMARY KEY (tenant_id, k))",
+		"DELETE FROM tenant_kv",
+	} {
+		if _, err := g.DB().Exec(ctx, sql); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	s := apijson.New()
+	s.Config().AccessListProvider = "upsertTenant"
+	s.Config().RequestListProvider = "upsertTenant"
+	s.Config().Access.TenantIdFunc = func(ctx context.Context) (any, error) {
+		return ctx.Value(tenantKey{}), nil
+	}
+	s.Load()
+
+	upsert := func(tenantId string, version string, v string) error {
+		ctx := context.WithValue(ctx, tenantKey{}, tenantId)
+		_, err := s.NewAction(ctx, consts.MethodUpsert, model.Map{"tag": "TenantKv", "version": version, "TenantKv": model.Map{"k": "a", "v": v}}).Result()
+		return err
+	}
+
+	// 冲突字段不含租户字段
+	if err := upsert("t1", "1", "1"); err == nil {
+		t.Fatal("conflict without tenant column")
+	}
+
+	if err := upsert("t1", "2", "1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := upsert("t2", "2", "2"); err != nil {
+		t.Fatal(err)
+	}
+
+	// 其他租户相同的 k 为新增, 不修改 t1 的行
+	rows, err := g.DB().GetAll(ctx, "SELECT tenant_id, v FROM tenant_kv ORDER BY tenant_id")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 2 || rows[0]["v"].String() != "1" || rows[1]["v"].String() != "2" {
+		t.Fatal(rows)
+	}
+}
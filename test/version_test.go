@@ -0,0 +1,114 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/glennliao/apijson-go"
+	"github.com/glennliao/apijson-go/config"
+	"github.com/glennliao/apijson-go/consts"
+	"github.com/glennliao/apijson-go/model"
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/os/gctx"
+	"github.com/gogf/gf/v2/util/gconv"
+)
+
+type versionUserKey struct{}
+
+func TestVersion(t *testing.T) {
+	ctx := gctx.New()
+
+	config.RegAccessListProvider("version", func(ctx context.Context) []config.AccessConfig {
+		return []config.AccessConfig{{Name: "doc_ver", Alias: "Doc", Put: []string{"OWNER", "ADMIN"}, RowKey: "id", VersionColumn: "ver"}}
+	})
+	config.RegRequestListProvider("version", func(ctx context.Context) []config.RequestConfig {
+		return []config.RequestConfig{{Tag: "Doc[]", Method: http.MethodPut, Version: "1", ExecQueue: []string{"Doc[]"}, Structure: map[string]*config.Structure{"Doc[]": {}}}}
+	})
+
+	for _, sql := range []string{
+		"CREATE TABLE IF NOT EXISTS doc_ver (id integer primary key, title text, ver integer not null default 0, user_id text)",
+		"DELETE FROM doc_ver",
+		"INSERT INTO doc_ver (id, title, user_id) VALUES (1, 'a', 'u1'), (2, 'b', 'u1')",
+	} {
+		if _, err := g.DB().Exec(ctx, sql); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	s := apijson.New()
+	s.Config().AccessListProvider = "version"
+	s.Config().RequestListProvider = "version"
+	s.Config().Access.DefaultRoleFunc = func(ctx context.Context, req config.RoleReq) (string, error) {
+		return req.NodeRole, nil
+	}
+	s.Config().Access.ConditionFunc = func(ctx context.Context, req config.ConditionReq, condition *config.ConditionRet) error {
+		if req.NodeRole == "OWNER" {
+			condition.Add("user_id", ctx.Value(versionUserKey{}))
+		}
+		return nil
+	}
+	s.Load()
+
+	u1 := context.WithValue(ctx, versionUserKey{}, "u1")
+	u2 := context.WithValue(ctx, versionUserKey{}, "u2")
+
+	put := func(ctx context.Context, role string, rows ...model.Map) (model.Map, error) {
+		for _, row := range rows {
+			row["@role"] = role
+		}
+		ret, err := s.NewAction(ctx, http.MethodPut, model.Map{"tag": "Doc[]", "Doc[]": rows}).Result()
+		if err != nil {
+			return nil, err
+		}
+		return gconv.Map(ret["Doc[]"]), nil
+	}
+	code := func(err error) int {
+		if e, ok := err.(consts.Err); ok {
+			return e.Code()
+		}
+		return 0
+	}
+	ver := func(id int) int {
+		v, err := g.DB().GetValue(ctx, "SELECT ver FROM doc_ver WHERE id = ?", id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return v.Int()
+	}
+
+	// 必须传入版本字段
+	if _, err := put(u1, "OWNER", model.Map{"id": 1, "title": "x"}); err == nil {
+		t.Fatal("put without version")
+	}
+
+	// 版本自增并返回新的版本号
+	ret, err := put(u1, "OWNER", model.Map{"id": 1, "ver": 0, "title": "x"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gconv.Int(ret["ver"]) != 1 || ver(1) != 1 {
+		t.Fatal(ret, ver(1))
+	}
+
+	// 过期的版本号
+	if _, err = put(u1, "OWNER", model.Map{"id": 1, "ver": 0, "title": "y"}); code(err) != 409 {
+		t.Fatal(err)
+	}
+
+	// condition 限制的行为无权限, 不存在的行为 404
+	if _, err = put(u2, "OWNER", model.Map{"id": 1, "ver": 1, "title": "y"}); code(err) != 403 {
+		t.Fatal(err)
+	}
+	if _, err = put(u1, "ADMIN", model.Map{"id": 3, "ver": 0, "title": "y"}); code(err) != 404 {
+		t.Fatal(err)
+	}
+
+	// 任一项冲突时全部回滚
+	if _, err = put(u1, "ADMIN", model.Map{"id": 1, "ver": 1, "title": "y"}, model.Map{"id": 2, "ver": 5, "title": "y"}); code(err) != 409 {
+		t.Fatal(err)
+	}
+	if ver(1) != 1 || ver(2) != 0 {
+		t.Fatal(ver(1), ver(2))
+	}
+}
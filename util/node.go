@@ -2,6 +2,8 @@ package util
 
 import (
 	"path/filepath"
+	"strings"
+	"unicode"
 
 	"github.com/glennliao/apijson-go/consts"
 	"github.com/glennliao/apijson-go/model"
@@ -29,6 +31,14 @@ func RemoveSuffix(key string, suffix string) string {
 	return key[0 : len(key)-len(suffix)]
 }
 
+// KeyFields 请求中的key去除操作符后的字段, 如 id{} -> [id], userId,roleId{} -> [userId roleId]
+func KeyFields(key string) []string {
+	key = strings.TrimRightFunc(key, func(r rune) bool {
+		return !(r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r))
+	})
+	return strings.Split(key, ",")
+}
+
 // ParseRefCol 解析引用字段
 // 将 "id@":"[]/User/userId"  解析出引用信息
 func ParseRefCol(refStr string) (refPath string, refCol string) {
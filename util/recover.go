@@ -0,0 +1,14 @@
+package util
+
+import "fmt"
+
+// RecoverErr 将 panic 转为 err, 需直接 defer 调用, 如 defer util.RecoverErr(&err, "lint config")
+func RecoverErr(err *error, msg string) {
+	if r := recover(); r != nil {
+		if e, ok := r.(error); ok {
+			*err = fmt.Errorf("%s: %w", msg, e)
+		} else {
+			*err = fmt.Errorf("%s: %v", msg, r)
+		}
+	}
+}